@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ramkansal/gofang/internal/diff"
+)
+
+// runDiff implements `gofang diff old.jsonl new.jsonl`.
+func runDiff(args []string) {
+	var files []string
+	asJSON := false
+	output := ""
+
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "-j", "--json":
+			asJSON = true
+		case "-o", "--output":
+			if i+1 >= len(args) {
+				fatal("flag %s requires an argument", arg)
+			}
+			i++
+			output = args[i]
+		case "-h", "--help":
+			printDiffUsage()
+			os.Exit(0)
+		default:
+			if strings.HasPrefix(arg, "-") {
+				fmt.Fprintf(os.Stderr, "Unknown flag: %s (use gofang diff --help for usage)\n", arg)
+				os.Exit(1)
+			}
+			files = append(files, arg)
+		}
+	}
+
+	if len(files) != 2 {
+		printDiffUsage()
+		os.Exit(1)
+	}
+
+	oldSnap, err := diff.Load(files[0])
+	if err != nil {
+		fatal("load %s: %v", files[0], err)
+	}
+	newSnap, err := diff.Load(files[1])
+	if err != nil {
+		fatal("load %s: %v", files[1], err)
+	}
+	report := diff.Compare(oldSnap, newSnap)
+
+	out := os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			fatal("create %s: %v", output, err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if asJSON {
+		if err := report.WriteJSON(out); err != nil {
+			fatal("write report: %v", err)
+		}
+		return
+	}
+	report.WriteText(out)
+}
+
+func printDiffUsage() {
+	fmt.Print(`
+USAGE:
+  gofang diff [flags] <old.jsonl> <new.jsonl>
+
+Compares two crawls saved with --jsonl and reports new/removed pages,
+status code and content changes, and extracted items that appeared or
+disappeared.
+
+FLAGS:
+  -j,    --json                      print the report as JSON
+  -o,    --output <string>           write the report to a file instead of stdout
+
+`)
+}
@@ -3,10 +3,12 @@ package main
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/ramkansal/gofang/internal/crawler"
+	"github.com/ramkansal/gofang/internal/extractor"
 	"github.com/ramkansal/gofang/pkg/plugin"
 )
 
@@ -17,6 +19,10 @@ type flags struct {
 	// Target
 	url string
 
+	// Replay
+	replay bool
+	input  string
+
 	// Crawl
 	depth             int
 	maxPages          int
@@ -25,6 +31,7 @@ type flags struct {
 	crawlDuration     time.Duration
 	strategy          string
 	ignoreQueryParams bool
+	nearDupThreshold  float64
 
 	// Request
 	userAgent        string
@@ -36,6 +43,9 @@ type flags struct {
 	resolvers        []string
 	disableRedirects bool
 	tlsImpersonate   bool
+	cookieFile       string
+	saveCookies      string
+	httpCache        string
 
 	// Features
 	external     bool
@@ -47,17 +57,59 @@ type flags struct {
 	formExtract  bool
 	techDetect   bool
 	fetcher      string
+	checkLinks   bool
+	checkPar     int
+	checkHost    int
+	scanJS       bool
+	secretRules  string
+	redact       string
+	secAudit     bool
+	a11yAudit    bool
+	extractors   []string
+	skipExt      []string
+	extTimeout   int
+
+	// Browser
+	browserInteract bool
+	maxScrolls      int
+	maxClicks       int
+	screenshots     bool
+	pdf             bool
+	captureDir      string
+	shotQuality     int
+	shotMaxHeight   int
+
+	// Auth
+	loginURL       string
+	loginUserField string
+	loginPassField string
+	loginSubmit    string
+	loginUserEnv   string
+	loginPassEnv   string
+	loginVia       string
+	loginCheck     string
 
 	// Output
-	output  string
-	silent  bool
-	verbose bool
-	noColor bool
+	output     string
+	harOutput  string
+	harMaxBody int
+	warcOutput string
+	warcMaxSz  int
+	jsonl      string
+	graph      string
+	baseline   string
+	diffOutput string
+	seoReport  string
+	silent     bool
+	verbose    bool
+	noColor    bool
 
 	// Config files
-	configFile  string
-	formConfig  string
-	fieldConfig string
+	configFile   string
+	formConfig   string
+	fieldConfig  string
+	extractRules string
+	socialPlats  string
 
 	// Meta
 	showHelp    bool
@@ -67,6 +119,11 @@ type flags struct {
 func main() {
 	enableANSI()
 
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		runDiff(os.Args[2:])
+		return
+	}
+
 	f := parseFlags()
 
 	if f.showVersion {
@@ -74,16 +131,22 @@ func main() {
 		os.Exit(0)
 	}
 
-	if f.showHelp || f.url == "" {
+	// Replay takes its seed from the archive, so a URL is optional there
+	missing := f.url == "" && !f.replay
+	if f.replay && f.input == "" {
+		fmt.Fprintf(os.Stderr, "replay requires an archive (-i crawl.warc.gz or -i crawl.har)\n")
+		missing = true
+	}
+	if f.showHelp || missing {
 		printUsage()
-		if f.url == "" && !f.showHelp {
+		if missing && !f.showHelp {
 			os.Exit(1)
 		}
 		os.Exit(0)
 	}
 
 	// Ensure URL has a scheme
-	if !strings.HasPrefix(f.url, "http://") && !strings.HasPrefix(f.url, "https://") {
+	if f.url != "" && !strings.HasPrefix(f.url, "http://") && !strings.HasPrefix(f.url, "https://") {
 		f.url = "https://" + f.url
 	}
 
@@ -139,6 +202,78 @@ func run(c *crawler.Crawler, cfg *crawler.CrawlConfig) {
 	if !cfg.Silent {
 		time.Sleep(50 * time.Millisecond)
 	}
+
+	if report := c.Diff(); report != nil && !cfg.Silent {
+		fmt.Printf("  %s Changes since %s\n", clr("cyan", "Δ"), cfg.Baseline)
+		report.WriteText(os.Stdout)
+		fmt.Println()
+	}
+
+	if hosts := c.Security(); len(hosts) > 0 && !cfg.Silent {
+		printSecurity(hosts)
+	}
+
+	if a := c.Accessibility(); a != nil && a.Findings > 0 && !cfg.Silent {
+		printAccessibility(a)
+	}
+
+	if report := c.SEO(); report != nil && !cfg.Silent {
+		fmt.Printf("  %s SEO audit (full report: %s)\n", clr("cyan", "⌕"), cfg.SEOReport)
+		report.WriteSummary(os.Stdout)
+		fmt.Println()
+	}
+}
+
+// printSecurity shows the per-host security audit rollup.
+func printSecurity(hosts []plugin.HostSecurity) {
+	fmt.Printf("  %s Security audit\n", clr("cyan", "⚑"))
+	for _, h := range hosts {
+		var sev []string
+		for _, name := range []string{"high", "medium", "low", "info"} {
+			if n := h.BySeverity[name]; n > 0 {
+				color := map[string]string{"high": "red", "medium": "yellow", "low": "cyan", "info": "dim"}[name]
+				sev = append(sev, fmt.Sprintf("%s:%s", clr("dim", name), clr(color, fmt.Sprintf("%d", n))))
+			}
+		}
+		fmt.Printf("    %s  %d pages, %d findings  %s\n", h.Host, h.Pages, h.Findings, strings.Join(sev, " "))
+
+		issues := make([]string, 0, len(h.Issues))
+		for issue := range h.Issues {
+			issues = append(issues, issue)
+		}
+		sort.Slice(issues, func(i, j int) bool {
+			if h.Issues[issues[i]] != h.Issues[issues[j]] {
+				return h.Issues[issues[i]] > h.Issues[issues[j]]
+			}
+			return issues[i] < issues[j]
+		})
+		for _, issue := range issues {
+			fmt.Printf("      %s %s\n", clr("dim", "├─ "+issue+":"), fmt.Sprintf("%d/%d pages", h.Issues[issue], h.Pages))
+		}
+	}
+	fmt.Println()
+}
+
+// printAccessibility shows the crawl's accessibility findings per issue.
+func printAccessibility(a *plugin.AccessibilitySummary) {
+	fmt.Printf("  %s Accessibility\n", clr("cyan", "♿"))
+	fmt.Printf("    %d pages, %d findings\n", a.Pages, a.Findings)
+
+	issues := make([]string, 0, len(a.PagesAffected))
+	for issue := range a.PagesAffected {
+		issues = append(issues, issue)
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if a.PagesAffected[issues[i]] != a.PagesAffected[issues[j]] {
+			return a.PagesAffected[issues[i]] > a.PagesAffected[issues[j]]
+		}
+		return issues[i] < issues[j]
+	})
+	for _, issue := range issues {
+		fmt.Printf("      %s %s\n", clr("dim", fmt.Sprintf("├─ %s (WCAG %s):", issue, a.WCAG[issue])),
+			fmt.Sprintf("%d findings on %d/%d pages", a.Issues[issue], a.PagesAffected[issue], a.Pages))
+	}
+	fmt.Println()
 }
 
 func handleEvent(event plugin.CrawlEvent, cfg *crawler.CrawlConfig) {
@@ -177,10 +312,56 @@ func handleEvent(event plugin.CrawlEvent, cfg *crawler.CrawlConfig) {
 				item.Value,
 			)
 		}
+		if p.DuplicateOf != "" {
+			fmt.Printf("      %s %s %s\n", clr("dim", "├─ duplicate of:"), p.DuplicateOf,
+				clr("dim", fmt.Sprintf("(%.0f%% similar, links not followed)", p.Similarity*100)))
+		}
+		if p.ScreenshotPath != "" {
+			fmt.Printf("      %s %s\n", clr("dim", "├─ screenshot:"), p.ScreenshotPath)
+		}
+		if p.PDFPath != "" {
+			fmt.Printf("      %s %s\n", clr("dim", "├─ pdf:"), p.PDFPath)
+		}
 
 	case plugin.EventPageError:
 		fmt.Printf("  %s %s\n", clr("red", "✗"), event.Message)
 
+	case plugin.EventExtractorError:
+		fmt.Printf("  %s %s\n", clr("yellow", "!"), event.Message)
+
+	case plugin.EventProgress:
+		fmt.Printf("\n  %s %s\n", clr("cyan", "…"), event.Message)
+
+	case plugin.EventLinkChecked:
+		l := event.Link
+		if l == nil || (!l.Broken && !cfg.Verbose) {
+			return
+		}
+		status := fmt.Sprintf("%d", l.StatusCode)
+		if l.StatusCode == 0 {
+			status = "ERR"
+		}
+		mark, color := clr("green", "✓"), "green"
+		if l.Broken {
+			mark, color = clr("red", "✗"), "red"
+		}
+		fmt.Printf("  %s [%s] %s %s\n", mark, clr(color, status), l.URL, clr("dim", l.Method))
+		for _, hop := range l.Redirects {
+			fmt.Printf("      %s %d %s\n", clr("dim", "├─ redirect:"), hop.StatusCode, hop.Location)
+		}
+		if l.Error != "" {
+			fmt.Printf("      %s %s\n", clr("dim", "├─ error:"), l.Error)
+		}
+		if l.Broken {
+			for i, src := range l.Sources {
+				if i == 3 {
+					fmt.Printf("      %s %d more\n", clr("dim", "├─ linked from:"), len(l.Sources)-i)
+					break
+				}
+				fmt.Printf("      %s %s\n", clr("dim", "├─ linked from:"), src)
+			}
+		}
+
 	case plugin.EventCrawlStarted:
 		// already printed in run()
 
@@ -196,6 +377,11 @@ func handleEvent(event plugin.CrawlEvent, cfg *crawler.CrawlConfig) {
 			clr("cyan", fmt.Sprintf("%d", s.PagesCrawled)),
 			clr("red", fmt.Sprintf("%d", s.PagesErrored)),
 		)
+		if s.PagesDuplicate > 0 {
+			fmt.Printf("    Dupes:  %s pages duplicate earlier content\n",
+				clr("yellow", fmt.Sprintf("%d", s.PagesDuplicate)),
+			)
+		}
 		fmt.Printf("    Items:  %s extracted in %s (%.1f pages/sec)\n",
 			clr("yellow", fmt.Sprintf("%d", s.ItemsExtracted)),
 			fmtDur(s.Elapsed),
@@ -204,7 +390,7 @@ func handleEvent(event plugin.CrawlEvent, cfg *crawler.CrawlConfig) {
 		if len(s.ItemsByType) > 0 {
 			fmt.Printf("    Types:  ")
 			first := true
-			for _, t := range []string{"link", "form", "email", "phone", "social", "metadata", "asset", "api_endpoint"} {
+			for _, t := range extractor.ItemTypes {
 				if count, ok := s.ItemsByType[t]; ok && count > 0 {
 					if !first {
 						fmt.Printf(", ")
@@ -215,9 +401,34 @@ func handleEvent(event plugin.CrawlEvent, cfg *crawler.CrawlConfig) {
 			}
 			fmt.Println()
 		}
+		if s.LinksChecked > 0 {
+			fmt.Printf("    Links:  %s checked, %s broken\n",
+				clr("cyan", fmt.Sprintf("%d", s.LinksChecked)),
+				clr("red", fmt.Sprintf("%d", s.LinksBroken)),
+			)
+		}
+		if s.CacheHits+s.CacheMisses > 0 {
+			fmt.Printf("    Cache:  %s hits, %s misses (%.0f%% hit ratio)\n",
+				clr("cyan", fmt.Sprintf("%d", s.CacheHits)),
+				clr("cyan", fmt.Sprintf("%d", s.CacheMisses)),
+				s.CacheHitRatio*100,
+			)
+		}
 		if cfg.SaveOutput {
 			fmt.Printf("    Output: %s\n", clr("green", cfg.OutputPath))
 		}
+		if cfg.HAROutput != "" {
+			fmt.Printf("    HAR:    %s\n", clr("green", cfg.HAROutput))
+		}
+		if cfg.WARCOutput != "" {
+			fmt.Printf("    WARC:   %s\n", clr("green", cfg.WARCOutput))
+		}
+		if cfg.JSONLOutput != "" {
+			fmt.Printf("    JSONL:  %s\n", clr("green", cfg.JSONLOutput))
+		}
+		if cfg.GraphOutput != "" {
+			fmt.Printf("    Graph:  %s\n", clr("green", cfg.GraphOutput))
+		}
 		fmt.Println()
 	}
 }
@@ -231,7 +442,7 @@ func itemCountStr(items []plugin.ExtractedItem) string {
 		counts[item.Type]++
 	}
 	var parts []string
-	for _, t := range []string{"link", "form", "email", "phone", "social", "metadata", "asset", "api_endpoint"} {
+	for _, t := range extractor.ItemTypes {
 		if c, ok := counts[t]; ok && c > 0 {
 			short := t
 			if t == "api_endpoint" {
@@ -240,6 +451,9 @@ func itemCountStr(items []plugin.ExtractedItem) string {
 			if t == "metadata" {
 				short = "meta"
 			}
+			if t == "structured_data" {
+				short = "schema"
+			}
 			parts = append(parts, fmt.Sprintf("%s:%d", short, c))
 		}
 	}
@@ -253,18 +467,36 @@ func itemCountStr(items []plugin.ExtractedItem) string {
 
 func parseFlags() *flags {
 	f := &flags{
-		depth:           3,
-		maxPages:        500,
-		parallel:        5,
-		timeout:         10,
-		retry:           1,
-		maxResponseSize: 4194304,
-		strategy:        "depth-first",
-		fetcher:         "http",
-		robots:          true,
+		depth:            3,
+		maxPages:         500,
+		parallel:         5,
+		timeout:          10,
+		retry:            1,
+		maxResponseSize:  4194304,
+		strategy:         "breadth-first",
+		nearDupThreshold: 0, // opt-in; pagination and listings look alike
+		fetcher:          "http",
+		robots:           true,
+		maxScrolls:       10,
+		maxClicks:        20,
+		captureDir:       "captures",
+		shotMaxHeight:    10000,
+		loginUserField:   "username",
+		loginPassField:   "password",
+		loginUserEnv:     "GOFANG_USER",
+		loginPassEnv:     "GOFANG_PASS",
+		loginVia:         "http",
+		harMaxBody:       1048576,
+		redact:           "partial",
+		extTimeout:       30,
+		warcMaxSz:        1073741824,
 	}
 
 	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "replay" {
+		f.replay = true
+		args = args[1:]
+	}
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
 		next := func() string {
@@ -286,6 +518,8 @@ func parseFlags() *flags {
 		// Target
 		case "-u", "--url":
 			f.url = next()
+		case "-i", "--input":
+			f.input = next()
 
 		// Crawl
 		case "-d", "--depth":
@@ -312,6 +546,11 @@ func parseFlags() *flags {
 			f.strategy = next()
 		case "-iqp", "--ignore-query-params":
 			f.ignoreQueryParams = true
+		case "-ndt", "--dup-threshold":
+			v := next()
+			if _, err := fmt.Sscanf(v, "%g", &f.nearDupThreshold); err != nil {
+				fatal("invalid near-duplicate threshold %q", v)
+			}
 
 		// Request
 		case "-ua", "--user-agent":
@@ -327,16 +566,17 @@ func parseFlags() *flags {
 		case "-H", "--header":
 			f.headers = append(f.headers, next())
 		case "-r", "--resolver":
-			v := next()
-			for _, r := range strings.Split(v, ",") {
-				if r = strings.TrimSpace(r); r != "" {
-					f.resolvers = append(f.resolvers, r)
-				}
-			}
+			f.resolvers = append(f.resolvers, splitList(next())...)
 		case "-dr", "--disable-redirects":
 			f.disableRedirects = true
 		case "-tlsi", "--tls-impersonate":
 			f.tlsImpersonate = true
+		case "-ck", "--cookies":
+			f.cookieFile = next()
+		case "-sck", "--save-cookies":
+			f.saveCookies = next()
+		case "-hc", "--http-cache":
+			f.httpCache = next()
 
 		// Features
 		case "-e", "--external":
@@ -357,10 +597,86 @@ func parseFlags() *flags {
 			f.techDetect = true
 		case "-f", "--fetcher":
 			f.fetcher = next()
+		case "-cl", "--check-links":
+			f.checkLinks = true
+		case "-clc", "--check-concurrency":
+			f.checkPar = nextInt()
+		case "-clh", "--check-per-host":
+			f.checkHost = nextInt()
+		case "-sjs", "--scan-js":
+			f.scanJS = true
+		case "-sr", "--secret-rules":
+			f.secretRules = next()
+		case "-rd", "--redact":
+			f.redact = next()
+		case "-sa", "--security-audit":
+			f.secAudit = true
+		case "-a11y", "--a11y-audit":
+			f.a11yAudit = true
+		case "-ex", "--extractors":
+			f.extractors = append(f.extractors, splitList(next())...)
+		case "-xx", "--skip-extractors":
+			f.skipExt = append(f.skipExt, splitList(next())...)
+		case "-xt", "--extractor-timeout":
+			f.extTimeout = nextInt()
+
+		// Browser
+		case "-bi", "--browser-interact":
+			f.browserInteract = true
+		case "-msc", "--max-scrolls":
+			f.maxScrolls = nextInt()
+		case "-mcl", "--max-clicks":
+			f.maxClicks = nextInt()
+		case "-ss", "--screenshot":
+			f.screenshots = true
+		case "-pdf", "--pdf":
+			f.pdf = true
+		case "-cd", "--capture-dir":
+			f.captureDir = next()
+		case "-sq", "--screenshot-quality":
+			f.shotQuality = nextInt()
+		case "-sh", "--screenshot-height":
+			f.shotMaxHeight = nextInt()
+
+		// Auth
+		case "-lu", "--login-url":
+			f.loginURL = next()
+		case "-luf", "--login-user-field":
+			f.loginUserField = next()
+		case "-lpf", "--login-pass-field":
+			f.loginPassField = next()
+		case "-ls", "--login-submit":
+			f.loginSubmit = next()
+		case "-lue", "--login-user-env":
+			f.loginUserEnv = next()
+		case "-lpe", "--login-pass-env":
+			f.loginPassEnv = next()
+		case "-lv", "--login-via":
+			f.loginVia = next()
+		case "-lc", "--login-check":
+			f.loginCheck = next()
 
 		// Output
 		case "-o", "--output":
 			f.output = next()
+		case "-har", "--har":
+			f.harOutput = next()
+		case "-hmb", "--har-max-body":
+			f.harMaxBody = nextInt()
+		case "-warc", "--warc":
+			f.warcOutput = next()
+		case "-wms", "--warc-max-size":
+			f.warcMaxSz = nextInt()
+		case "-jl", "--jsonl":
+			f.jsonl = next()
+		case "-gr", "--graph":
+			f.graph = next()
+		case "-bl", "--baseline":
+			f.baseline = next()
+		case "-do", "--diff-output":
+			f.diffOutput = next()
+		case "-seo", "--seo-report":
+			f.seoReport = next()
 		case "-si", "--silent":
 			f.silent = true
 		case "-v", "--verbose":
@@ -375,6 +691,10 @@ func parseFlags() *flags {
 			f.formConfig = next()
 		case "-flc", "--field-config":
 			f.fieldConfig = next()
+		case "-er", "--extract-rules":
+			f.extractRules = next()
+		case "-spf", "--social-platforms":
+			f.socialPlats = next()
 
 		// Meta
 		case "-h", "--help":
@@ -406,6 +726,7 @@ func buildConfig(f *flags) *crawler.CrawlConfig {
 	cfg.MaxResponseSize = f.maxResponseSize
 	cfg.Strategy = crawler.Strategy(f.strategy)
 	cfg.IgnoreQueryParams = f.ignoreQueryParams
+	cfg.NearDupThreshold = f.nearDupThreshold
 	cfg.AllowExternal = f.external
 	cfg.RespectRobots = f.robots
 	cfg.JSCrawl = f.jsCrawl
@@ -414,8 +735,40 @@ func buildConfig(f *flags) *crawler.CrawlConfig {
 	cfg.AutoFormFill = f.autoFormFill
 	cfg.FormExtraction = f.formExtract
 	cfg.TechDetect = f.techDetect
+	cfg.CheckLinks = f.checkLinks
+	cfg.CheckParallel = f.checkPar
+	cfg.CheckPerHost = f.checkHost
+	cfg.ScanScripts = f.scanJS
+	cfg.SecretRules = f.secretRules
+	cfg.Redact = f.redact
+	cfg.SecurityAudit = f.secAudit
+	cfg.A11yAudit = f.a11yAudit
+	cfg.Extractors = f.extractors
+	cfg.ExcludeExtractors = f.skipExt
+	cfg.ExtractorTimeout = time.Duration(f.extTimeout) * time.Second
 	cfg.DisableRedirects = f.disableRedirects
 	cfg.TLSImpersonate = f.tlsImpersonate
+	cfg.CookieFile = f.cookieFile
+	cfg.SaveCookies = f.saveCookies
+	cfg.HTTPCacheDir = f.httpCache
+	cfg.BrowserInteract = f.browserInteract
+	cfg.MaxScrolls = f.maxScrolls
+	cfg.MaxClicks = f.maxClicks
+	cfg.Screenshots = f.screenshots
+	cfg.PDF = f.pdf
+	cfg.CaptureDir = f.captureDir
+	cfg.ScreenshotQuality = f.shotQuality
+	cfg.ScreenshotMaxHeight = f.shotMaxHeight
+	cfg.LoginURL = f.loginURL
+	cfg.LoginUserField = f.loginUserField
+	cfg.LoginPassField = f.loginPassField
+	cfg.LoginSubmit = f.loginSubmit
+	cfg.LoginUserEnv = f.loginUserEnv
+	cfg.LoginPassEnv = f.loginPassEnv
+	cfg.LoginCheck = f.loginCheck
+	if strings.ToLower(f.loginVia) == "browser" {
+		cfg.LoginVia = crawler.FetcherBrowser
+	}
 	cfg.Proxy = f.proxy
 	cfg.CustomHeaders = f.headers
 	cfg.CustomResolvers = f.resolvers
@@ -425,6 +778,8 @@ func buildConfig(f *flags) *crawler.CrawlConfig {
 	cfg.ConfigFile = f.configFile
 	cfg.FormConfig = f.formConfig
 	cfg.FieldConfig = f.fieldConfig
+	cfg.ExtractRules = f.extractRules
+	cfg.SocialPlatforms = f.socialPlats
 
 	if f.rateLimit > 0 {
 		cfg.RateLimit = f.rateLimit
@@ -445,10 +800,27 @@ func buildConfig(f *flags) *crawler.CrawlConfig {
 		cfg.FetcherMode = crawler.FetcherAuto
 	}
 
+	// Replay runs a single worker so results come out in the same order
+	// every time
+	if f.replay {
+		cfg.FetcherMode = crawler.FetcherReplay
+		cfg.ReplayInput = f.input
+		cfg.Parallelism = 1
+	}
+
 	if f.output != "" {
 		cfg.SaveOutput = true
 		cfg.OutputPath = f.output
 	}
+	cfg.HAROutput = f.harOutput
+	cfg.HARMaxBodySize = f.harMaxBody
+	cfg.WARCOutput = f.warcOutput
+	cfg.WARCMaxSize = int64(f.warcMaxSz)
+	cfg.JSONLOutput = f.jsonl
+	cfg.GraphOutput = f.graph
+	cfg.Baseline = f.baseline
+	cfg.DiffOutput = f.diffOutput
+	cfg.SEOReport = f.seoReport
 
 	return cfg
 }
@@ -462,18 +834,22 @@ USAGE:
   gofang [flags] <url>
   gofang -u https://example.com
   gofang -u https://example.com -d 5 -c 10 -f browser
+  gofang replay -i crawl.warc.gz [flags]
+  gofang diff [-j] old.jsonl new.jsonl
 
 TARGET:
   -u,    --url <string>              target URL to crawl
+  -i,    --input <string>            WARC or HAR archive to replay (replay only; seed defaults to its first URL)
 
 CRAWL:
   -d,    --depth <int>               maximum depth to crawl (default 3)
-  -mp,   --max-pages <int>           maximum number of pages to crawl (default 500)
+  -mp,   --max-pages <int>           maximum number of pages to crawl, 0 for no limit (default 500)
   -c,    --concurrency <int>         number of concurrent crawl workers (default 5)
   -rl,   --rate-limit <duration>     delay between requests (default 200ms)
   -ct,   --crawl-duration <duration> maximum duration to crawl the target for (e.g. 30s, 5m, 1h)
-  -s,    --strategy <string>         visit strategy: depth-first, breadth-first (default "depth-first")
+  -s,    --strategy <string>         visit strategy: depth-first, breadth-first (default "breadth-first")
   -iqp,  --ignore-query-params       ignore crawling same path with different query-param values
+  -ndt,  --dup-threshold <float>     similarity (0-1) at which pages count as near-duplicates, e.g. 0.9 (default 0, disabled)
 
 REQUEST:
   -ua,   --user-agent <string>       custom user-agent string
@@ -485,6 +861,9 @@ REQUEST:
   -r,    --resolver <string>         list of custom resolvers, comma separated
   -dr,   --disable-redirects         disable following redirects
   -tlsi, --tls-impersonate           enable experimental client hello (ja3) tls randomization
+  -ck,   --cookies <string>          load cookies from a Netscape cookies.txt, JSON or HAR file
+  -sck,  --save-cookies <string>     save the cookie jar at the end of the crawl (.json or cookies.txt)
+  -hc,   --http-cache <string>       cache responses in this directory and revalidate them on recrawl
 
 FEATURES:
   -e,    --external                  follow and extract external links
@@ -495,18 +874,60 @@ FEATURES:
   -fx,   --form-extraction           extract form, input, textarea & select elements in output
   -td,   --tech-detect               enable technology detection
   -f,    --fetcher <string>          fetcher mode: http, browser, auto (default "http")
+  -cl,   --check-links               check every discovered link and asset (HEAD, falling back to GET)
+  -clc,  --check-concurrency <int>   concurrent link checks (default 10)
+  -clh,  --check-per-host <int>      concurrent link checks per host (default 2)
+  -sjs,  --scan-js                   fetch in-scope JS files and scan them for secrets
+  -sr,   --secret-rules <string>     YAML file of extra secret-detection rules
+  -rd,   --redact <string>           redact secrets in output: none, partial, full (default "partial")
+  -sa,   --security-audit            audit security headers and cookies on every page
+  -a11y, --a11y-audit                check every page for WCAG accessibility issues
+  -ex,   --extractors <string>       run only these extractors, comma separated (e.g. emails,phones)
+  -xx,   --skip-extractors <string>  do not run these extractors, comma separated (e.g. assets)
+  -xt,   --extractor-timeout <int>   time limit per extractor and page in seconds, 0 disables (default 30)
          --no-robots                 ignore robots.txt restrictions
 
+BROWSER:
+  -bi,   --browser-interact          scroll and click through pages to reveal lazy content
+  -msc,  --max-scrolls <int>         maximum scroll steps per page (default 10)
+  -mcl,  --max-clicks <int>          maximum safe clicks per page (default 20)
+  -ss,   --screenshot                save a full-page screenshot of every page
+  -pdf,  --pdf                       save a PDF print of every page
+  -cd,   --capture-dir <string>      directory for screenshots and PDFs (default "captures")
+  -sq,   --screenshot-quality <int>  save screenshots as JPEG with this quality (1-99, default PNG)
+  -sh,   --screenshot-height <int>   clip screenshots to this many pixels (default 10000)
+
+AUTH:
+  -lu,   --login-url <string>        login page URL; enables authenticated crawling
+  -luf,  --login-user-field <string> username field name or CSS selector (default "username")
+  -lpf,  --login-pass-field <string> password field name or CSS selector (default "password")
+  -ls,   --login-submit <string>     CSS selector of the submit button (browser login)
+  -lue,  --login-user-env <string>   env var holding the username (default "GOFANG_USER")
+  -lpe,  --login-pass-env <string>   env var holding the password (default "GOFANG_PASS")
+  -lv,   --login-via <string>        perform login with: http, browser (default "http")
+  -lc,   --login-check <string>      regex on page body that signals a logged-out session
+
 OUTPUT:
   -o,    --output <string>           save terminal output to file (disabled by default)
+  -har,  --har <string>              record every request/response to a HAR 1.2 file
+  -hmb,  --har-max-body <int>        maximum response body bytes stored per HAR entry (default 1048576)
+  -warc, --warc <string>             archive crawled pages as gzipped WARC 1.1 files with this prefix
+  -wms,  --warc-max-size <int>       rotate WARC files after this many bytes (default 1073741824)
+  -jl,   --jsonl <string>            write one JSON result per page to a JSONL file
+  -gr,   --graph <string>            export the link graph with PageRank (.graphml, .dot or .json)
+  -bl,   --baseline <string>         compare this crawl against a previous JSONL file
+  -do,   --diff-output <string>      save the baseline diff report (.json for JSON, else text)
+  -seo,  --seo-report <string>       save the site-wide SEO report (.json for JSON, else text)
   -si,   --silent                    suppress all output except errors
   -v,    --verbose                   show detailed extraction results per page
   -nc,   --no-color                  disable colored output
 
 CONFIG:
-         --config <string>           path to crawler configuration file
+         --config <string>           path to crawler configuration file (plugins)
   -fc,   --form-config <string>      path to custom form configuration file
   -flc,  --field-config <string>     path to custom field configuration file
+  -er,   --extract-rules <string>    YAML file of declarative extraction rules (regex, CSS, XPath)
+  -spf,  --social-platforms <string> YAML file of extra social platforms and Mastodon instances
 
 META:
   -h,    --help                      show this help message
@@ -563,3 +984,14 @@ func fatal(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, "\n  %s %s\n\n", clr("red", "ERROR:"), fmt.Sprintf(format, args...))
 	os.Exit(1)
 }
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,324 @@
+package cookies
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpOnlyPrefix marks HttpOnly cookies in curl-style Netscape files.
+const httpOnlyPrefix = "#HttpOnly_"
+
+// LoadFile reads cookies from path into the jar and returns how many were
+// loaded. The format is detected from the content: a HAR archive, a JSON
+// cookie export (array, or an object with a "cookies" array), or a
+// Netscape cookies.txt file.
+func LoadFile(path string, jar *Jar) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var cookies []Cookie
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		cookies, err = parseJSONArray(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		cookies, err = parseJSONObject(trimmed)
+	default:
+		cookies, err = parseNetscape(trimmed)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for _, c := range cookies {
+		jar.Add(c)
+	}
+	return len(cookies), nil
+}
+
+// SaveFile writes every cookie in the jar to path. Files ending in .json are
+// written as a JSON array; anything else as Netscape cookies.txt.
+func SaveFile(path string, jar *Jar) error {
+	var data []byte
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		var err error
+		if data, err = marshalJSON(jar.All()); err != nil {
+			return err
+		}
+	} else {
+		data = marshalNetscape(jar.All())
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// ---------- Netscape cookies.txt ----------
+
+func parseNetscape(data []byte) ([]Cookie, error) {
+	var cookies []Cookie
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := false
+		if strings.HasPrefix(line, httpOnlyPrefix) {
+			httpOnly = true
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("line %d: expected 7 tab-separated fields, got %d", lineNo, len(fields))
+		}
+
+		c := Cookie{
+			Domain:   fields[0],
+			HostOnly: !strings.EqualFold(fields[1], "TRUE") && !strings.HasPrefix(fields[0], "."),
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    strings.Join(fields[6:], "\t"),
+			HttpOnly: httpOnly,
+		}
+		if exp, err := strconv.ParseInt(fields[4], 10, 64); err == nil && exp > 0 {
+			c.Expires = time.Unix(exp, 0)
+		}
+		cookies = append(cookies, c)
+	}
+	return cookies, scanner.Err()
+}
+
+func marshalNetscape(cookies []Cookie) []byte {
+	var b bytes.Buffer
+	b.WriteString("# Netscape HTTP Cookie File\n")
+	b.WriteString("# Generated by gofang\n\n")
+	for _, c := range cookies {
+		domain := c.Domain
+		subdomains := "FALSE"
+		if !c.HostOnly {
+			domain = "." + domain
+			subdomains = "TRUE"
+		}
+		if c.HttpOnly {
+			domain = httpOnlyPrefix + domain
+		}
+		var exp int64
+		if !c.Expires.IsZero() {
+			exp = c.Expires.Unix()
+		}
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, subdomains, c.Path, strings.ToUpper(strconv.FormatBool(c.Secure)), exp, c.Name, c.Value)
+	}
+	return b.Bytes()
+}
+
+// ---------- JSON exports ----------
+
+// jsonCookie covers the common browser-extension, Puppeteer/Playwright and
+// CDP cookie export shapes.
+type jsonCookie struct {
+	Name           string   `json:"name"`
+	Value          string   `json:"value"`
+	Domain         string   `json:"domain"`
+	Path           string   `json:"path"`
+	Secure         bool     `json:"secure"`
+	HttpOnly       bool     `json:"httpOnly"`
+	HostOnly       *bool    `json:"hostOnly,omitempty"`
+	SameSite       string   `json:"sameSite,omitempty"`
+	Session        bool     `json:"session,omitempty"`
+	Expires        *float64 `json:"expires,omitempty"`
+	ExpirationDate *float64 `json:"expirationDate,omitempty"`
+}
+
+func (jc jsonCookie) toCookie() Cookie {
+	c := Cookie{
+		Name:     jc.Name,
+		Value:    jc.Value,
+		Domain:   jc.Domain,
+		Path:     jc.Path,
+		Secure:   jc.Secure,
+		HttpOnly: jc.HttpOnly,
+		SameSite: parseSameSite(jc.SameSite),
+	}
+	if jc.HostOnly != nil {
+		c.HostOnly = *jc.HostOnly
+	} else {
+		c.HostOnly = !strings.HasPrefix(jc.Domain, ".")
+	}
+
+	exp := jc.ExpirationDate
+	if exp == nil {
+		exp = jc.Expires
+	}
+	if !jc.Session && exp != nil && *exp > 0 {
+		sec, frac := math.Modf(*exp)
+		c.Expires = time.Unix(int64(sec), int64(frac*1e9))
+	}
+	return c
+}
+
+func parseJSONArray(data []byte) ([]Cookie, error) {
+	var raw []jsonCookie
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	cookies := make([]Cookie, 0, len(raw))
+	for _, jc := range raw {
+		if jc.Name == "" || jc.Domain == "" {
+			continue
+		}
+		cookies = append(cookies, jc.toCookie())
+	}
+	return cookies, nil
+}
+
+// parseJSONObject handles Playwright storage state ({"cookies": [...]}) and
+// HAR archives ({"log": {"entries": [...]}}).
+func parseJSONObject(data []byte) ([]Cookie, error) {
+	var obj struct {
+		Cookies json.RawMessage `json:"cookies"`
+		Log     *harLog         `json:"log"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	if obj.Log != nil {
+		return obj.Log.cookies(), nil
+	}
+	if len(obj.Cookies) > 0 {
+		return parseJSONArray(obj.Cookies)
+	}
+	return nil, fmt.Errorf("no cookies found in JSON object")
+}
+
+func marshalJSON(cookies []Cookie) ([]byte, error) {
+	out := make([]jsonCookie, 0, len(cookies))
+	for _, c := range cookies {
+		hostOnly := c.HostOnly
+		domain := c.Domain
+		if !hostOnly {
+			domain = "." + domain
+		}
+		jc := jsonCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			HostOnly: &hostOnly,
+			SameSite: formatSameSite(c.SameSite),
+			Session:  c.Expires.IsZero(),
+		}
+		if !c.Expires.IsZero() {
+			exp := float64(c.Expires.Unix())
+			jc.Expires = &exp
+		}
+		out = append(out, jc)
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+// ---------- HAR ----------
+
+type harLog struct {
+	Entries []struct {
+		Request struct {
+			URL     string      `json:"url"`
+			Cookies []harCookie `json:"cookies"`
+		} `json:"request"`
+		Response struct {
+			Cookies []harCookie `json:"cookies"`
+		} `json:"response"`
+	} `json:"entries"`
+}
+
+type harCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Domain   string `json:"domain"`
+	Path     string `json:"path"`
+	Expires  string `json:"expires"`
+	HttpOnly bool   `json:"httpOnly"`
+	Secure   bool   `json:"secure"`
+}
+
+// cookies collects cookies from every entry. Later entries win, so the
+// result reflects the session state at the end of the recording.
+func (l *harLog) cookies() []Cookie {
+	var out []Cookie
+	for _, e := range l.Entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil {
+			continue
+		}
+		host := canonicalHost(u.Host)
+		for _, hc := range append(e.Request.Cookies, e.Response.Cookies...) {
+			c := Cookie{
+				Name:     hc.Name,
+				Value:    hc.Value,
+				Domain:   hc.Domain,
+				Path:     hc.Path,
+				Secure:   hc.Secure,
+				HttpOnly: hc.HttpOnly,
+			}
+			if c.Domain == "" {
+				c.Domain = host
+				c.HostOnly = true
+			}
+			if c.Path == "" {
+				c.Path = "/"
+			}
+			if hc.Expires != "" {
+				if t, err := time.Parse(time.RFC3339, hc.Expires); err == nil {
+					c.Expires = t
+				}
+			}
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// ---------- helpers ----------
+
+func parseSameSite(s string) http.SameSite {
+	switch strings.ToLower(s) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none", "no_restriction":
+		return http.SameSiteNoneMode
+	default:
+		return 0
+	}
+}
+
+func formatSameSite(s http.SameSite) string {
+	switch s {
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteNoneMode:
+		return "None"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,243 @@
+// Package cookies provides the cookie jar shared by all fetchers, plus
+// import and export in Netscape cookies.txt, JSON and HAR formats.
+package cookies
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Cookie is a stored cookie with its full scope, unlike the name/value pairs
+// returned by http.CookieJar.Cookies.
+type Cookie struct {
+	Name     string
+	Value    string
+	Domain   string
+	Path     string
+	HostOnly bool
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+	Expires  time.Time // zero for session cookies
+}
+
+func (c *Cookie) key() string {
+	return c.Domain + ";" + c.Path + ";" + c.Name
+}
+
+func (c *Cookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+// Jar is an http.CookieJar that respects domain, path and secure scoping
+// and can enumerate its contents for export.
+type Jar struct {
+	mu      sync.Mutex
+	entries map[string]*Cookie
+}
+
+// NewJar creates an empty cookie jar.
+func NewJar() *Jar {
+	return &Jar{entries: make(map[string]*Cookie)}
+}
+
+// SetCookies implements http.CookieJar.
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	host := canonicalHost(u.Host)
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, hc := range cookies {
+		c := &Cookie{
+			Name:     hc.Name,
+			Value:    hc.Value,
+			Path:     hc.Path,
+			Secure:   hc.Secure,
+			HttpOnly: hc.HttpOnly,
+			SameSite: hc.SameSite,
+		}
+
+		domain := strings.TrimPrefix(strings.ToLower(hc.Domain), ".")
+		if domain != "" && publicsuffix.List.PublicSuffix(domain) == domain {
+			// Domain=com or Domain=co.uk would be a supercookie; a host
+			// that is itself a public suffix only gets a host-only cookie
+			if host != domain {
+				continue
+			}
+			domain = ""
+		}
+		if domain == "" {
+			c.Domain = host
+			c.HostOnly = true
+		} else if domainMatch(host, domain) {
+			c.Domain = domain
+		} else {
+			// A site may not set cookies for a domain it doesn't belong to
+			continue
+		}
+
+		if c.Path == "" || !strings.HasPrefix(c.Path, "/") {
+			c.Path = defaultPath(u.Path)
+		}
+
+		switch {
+		case hc.MaxAge < 0:
+			c.Expires = now
+		case hc.MaxAge > 0:
+			c.Expires = now.Add(time.Duration(hc.MaxAge) * time.Second)
+		default:
+			c.Expires = hc.Expires
+		}
+
+		if c.expired(now) {
+			delete(j.entries, c.key())
+			continue
+		}
+		j.entries[c.key()] = c
+	}
+}
+
+// Cookies implements http.CookieJar.
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	matched := j.Scoped(u)
+	out := make([]*http.Cookie, len(matched))
+	for i, c := range matched {
+		out[i] = &http.Cookie{Name: c.Name, Value: c.Value}
+	}
+	return out
+}
+
+// Scoped returns the cookies that would be sent to u with their full
+// scope, most specific path first.
+func (j *Jar) Scoped(u *url.URL) []Cookie {
+	host := canonicalHost(u.Host)
+	secure := u.Scheme == "https" || u.Scheme == "wss"
+	reqPath := u.Path
+	if reqPath == "" {
+		reqPath = "/"
+	}
+	now := time.Now()
+
+	j.mu.Lock()
+	var matched []Cookie
+	for key, c := range j.entries {
+		if c.expired(now) {
+			delete(j.entries, key)
+			continue
+		}
+		if c.HostOnly && host != c.Domain {
+			continue
+		}
+		if !c.HostOnly && !domainMatch(host, c.Domain) {
+			continue
+		}
+		if c.Secure && !secure {
+			continue
+		}
+		if !pathMatch(reqPath, c.Path) {
+			continue
+		}
+		matched = append(matched, *c)
+	}
+	j.mu.Unlock()
+
+	// More specific paths first, as browsers do
+	sort.SliceStable(matched, func(a, b int) bool {
+		return len(matched[a].Path) > len(matched[b].Path)
+	})
+	return matched
+}
+
+// Add stores a fully-scoped cookie, e.g. one loaded from a file. An
+// expired cookie removes the stored one with the same scope instead.
+func (j *Jar) Add(c Cookie) {
+	c.Domain = strings.TrimPrefix(strings.ToLower(c.Domain), ".")
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if c.expired(time.Now()) {
+		delete(j.entries, c.key())
+		return
+	}
+	j.entries[c.key()] = &c
+}
+
+// All returns every unexpired cookie, ordered by domain, path and name.
+func (j *Jar) All() []Cookie {
+	now := time.Now()
+
+	j.mu.Lock()
+	out := make([]Cookie, 0, len(j.entries))
+	for _, c := range j.entries {
+		if !c.expired(now) {
+			out = append(out, *c)
+		}
+	}
+	j.mu.Unlock()
+
+	sort.Slice(out, func(a, b int) bool {
+		if out[a].Domain != out[b].Domain {
+			return out[a].Domain < out[b].Domain
+		}
+		if out[a].Path != out[b].Path {
+			return out[a].Path < out[b].Path
+		}
+		return out[a].Name < out[b].Name
+	})
+	return out
+}
+
+// Len returns the number of stored cookies.
+func (j *Jar) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.entries)
+}
+
+// canonicalHost lowercases the host and strips any port.
+func canonicalHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+// domainMatch implements RFC 6265 section 5.1.3.
+func domainMatch(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// pathMatch implements RFC 6265 section 5.1.4.
+func pathMatch(reqPath, cookiePath string) bool {
+	if reqPath == cookiePath {
+		return true
+	}
+	if strings.HasPrefix(reqPath, cookiePath) {
+		return strings.HasSuffix(cookiePath, "/") || reqPath[len(cookiePath)] == '/'
+	}
+	return false
+}
+
+// defaultPath implements RFC 6265 section 5.1.4 default-path.
+func defaultPath(p string) string {
+	if p == "" || p[0] != '/' {
+		return "/"
+	}
+	dir := path.Dir(p)
+	if dir == "." {
+		return "/"
+	}
+	return dir
+}
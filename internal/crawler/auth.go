@@ -0,0 +1,149 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/ramkansal/gofang/internal/fetcher"
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// maxReauth caps how many times a crawl re-authenticates, so bad
+// credentials or a login loop can't stall the crawl.
+const maxReauth = 5
+
+// loginer is implemented by fetchers that can perform a form login.
+type loginer interface {
+	Login(form fetcher.LoginForm) error
+}
+
+// session keeps the crawl authenticated, logging in again when a response
+// shows the session has expired.
+type session struct {
+	form      fetcher.LoginForm
+	via       loginer
+	loginURL  *url.URL
+	expiredRe *regexp.Regexp
+	logoutRe  *regexp.Regexp
+
+	mu         sync.Mutex
+	generation int
+	attempts   int
+}
+
+// newSession builds a session from the crawl config, reading credentials
+// from the configured environment variables.
+func newSession(cfg *CrawlConfig, via loginer) (*session, error) {
+	loginURL, err := url.Parse(cfg.LoginURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid login URL: %w", err)
+	}
+
+	username := os.Getenv(cfg.LoginUserEnv)
+	if username == "" {
+		return nil, fmt.Errorf("login: $%s is not set", cfg.LoginUserEnv)
+	}
+	password := os.Getenv(cfg.LoginPassEnv)
+	if password == "" {
+		return nil, fmt.Errorf("login: $%s is not set", cfg.LoginPassEnv)
+	}
+
+	s := &session{
+		form: fetcher.LoginForm{
+			URL:           cfg.LoginURL,
+			UsernameField: cfg.LoginUserField,
+			PasswordField: cfg.LoginPassField,
+			SubmitButton:  cfg.LoginSubmit,
+			Username:      username,
+			Password:      password,
+		},
+		via:      via,
+		loginURL: loginURL,
+	}
+
+	if cfg.LoginCheck != "" {
+		if s.expiredRe, err = regexp.Compile(cfg.LoginCheck); err != nil {
+			return nil, fmt.Errorf("invalid login check pattern: %w", err)
+		}
+	}
+	if cfg.LogoutPattern != "" {
+		if s.logoutRe, err = regexp.Compile(cfg.LogoutPattern); err != nil {
+			return nil, fmt.Errorf("invalid logout pattern: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// authenticate performs the initial login.
+func (s *session) authenticate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.via.Login(s.form); err != nil {
+		return err
+	}
+	s.generation++
+	return nil
+}
+
+// current returns the login generation, to be passed back to reauthenticate.
+func (s *session) current() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generation
+}
+
+// reauthenticate logs in again unless another worker already did so since
+// seen was read.
+func (s *session) reauthenticate(seen int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.generation != seen {
+		return nil
+	}
+	if s.attempts >= maxReauth {
+		return fmt.Errorf("giving up after %d re-authentication attempts", maxReauth)
+	}
+	s.attempts++
+
+	if err := s.via.Login(s.form); err != nil {
+		return err
+	}
+	s.generation++
+	return nil
+}
+
+// expired reports whether the page indicates a logged-out session:
+// a 401, a redirect to the login page, or a match of the login check pattern.
+func (s *session) expired(page *plugin.PageData) bool {
+	if page == nil || s.isLoginPage(page.URL) {
+		return false
+	}
+	if page.StatusCode == 401 || s.isLoginPage(page.FinalURL) {
+		return true
+	}
+	if s.expiredRe != nil && s.expiredRe.MatchString(page.RawHTML) {
+		return true
+	}
+	return false
+}
+
+// isLogout reports whether following rawURL would end the session.
+func (s *session) isLogout(rawURL string) bool {
+	return s.logoutRe != nil && s.logoutRe.MatchString(rawURL)
+}
+
+func (s *session) isLoginPage(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, s.loginURL.Host) &&
+		strings.TrimRight(u.Path, "/") == strings.TrimRight(s.loginURL.Path, "/")
+}
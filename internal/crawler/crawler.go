@@ -1,15 +1,27 @@
 package crawler
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net/url"
+	"os"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ramkansal/gofang/internal/cookies"
+	"github.com/ramkansal/gofang/internal/dedup"
+	"github.com/ramkansal/gofang/internal/diff"
 	"github.com/ramkansal/gofang/internal/extractor"
 	"github.com/ramkansal/gofang/internal/fetcher"
+	"github.com/ramkansal/gofang/internal/linkcheck"
 	"github.com/ramkansal/gofang/internal/output"
+	"github.com/ramkansal/gofang/internal/seo"
 	"github.com/ramkansal/gofang/pkg/plugin"
 )
 
@@ -18,9 +30,31 @@ type Crawler struct {
 	config     *CrawlConfig
 	httpFetch  plugin.Fetcher
 	browFetch  plugin.Fetcher
+	replay     *fetcher.ReplayFetcher
 	extractors *extractor.Registry
-	writer     plugin.OutputWriter
+	hideLinks  bool // links extractor deselected: follow links, don't report them
+	writers    []plugin.OutputWriter
+	plugins    []io.Closer // external plugin processes
+	custom     customPlugins
 	events     chan plugin.CrawlEvent
+	handler    func(plugin.CrawlEvent)
+	handlerMu  sync.Mutex
+	jar        *cookies.Jar
+	session    *session
+	dupes      *dedup.Index
+	checker    *linkcheck.Checker
+	linkChecks []plugin.LinkStatus
+
+	// Change detection against a previous run
+	baseline *diff.Snapshot
+	current  *diff.Snapshot
+	report   *diff.Report
+
+	// Site-wide SEO audit
+	seo       *seo.Auditor
+	seoReport *seo.Report
+
+	summary *plugin.CrawlSummary
 
 	// URL frontier
 	visited map[string]bool
@@ -30,12 +64,15 @@ type Crawler struct {
 
 	// Stats
 	stats     plugin.CrawlStats
+	security  map[string]*plugin.HostSecurity // per-host audit rollup
+	a11y      plugin.AccessibilitySummary
 	statsMu   sync.Mutex
 	startTime time.Time
 
 	// Control
 	done    chan struct{}
 	stopped bool
+	cancel  context.CancelFunc
 	stopMu  sync.Mutex
 }
 
@@ -62,8 +99,31 @@ func (c *Crawler) Events() <-chan plugin.CrawlEvent {
 	return c.events
 }
 
+// SetEventHandler delivers events to fn instead of the event channel.
+// fn is called synchronously, one event at a time, so unlike the channel
+// no event is ever dropped; a slow fn slows the crawl down. It must be
+// set before Run.
+func (c *Crawler) SetEventHandler(fn func(plugin.CrawlEvent)) {
+	c.handler = fn
+}
+
 // Init initializes all components (fetchers, extractors, output).
 func (c *Crawler) Init() error {
+	// Replay serves everything from an archive; the seed defaults to its first URL
+	if c.config.FetcherMode == FetcherReplay {
+		rf, err := fetcher.NewReplayFetcher(c.config.ReplayInput)
+		if err != nil {
+			return fmt.Errorf("load replay archive: %w", err)
+		}
+		c.replay = rf
+		if c.config.TargetURL == "" {
+			c.config.TargetURL = rf.Seed()
+		}
+		if c.config.LoginURL != "" {
+			return fmt.Errorf("login is not supported when replaying an archive")
+		}
+	}
+
 	// Parse target domain for Colly's allowed domains
 	parsedURL, err := url.Parse(c.config.TargetURL)
 	if err != nil {
@@ -71,30 +131,89 @@ func (c *Crawler) Init() error {
 	}
 	domain := parsedURL.Hostname()
 
-	// Initialize HTTP fetcher
-	c.httpFetch = fetcher.NewHTTPFetcher(fetcher.HTTPFetcherConfig{
-		MaxDepth:         c.config.MaxDepth,
-		Parallelism:      c.config.Parallelism,
-		RateLimit:        c.config.RateLimit,
-		UserAgent:        c.config.UserAgent,
-		AllowExternal:    c.config.AllowExternal,
-		RespectRobots:    c.config.RespectRobots,
-		AllowedDomain:    domain,
-		Timeout:          c.config.Timeout,
-		Retry:            c.config.Retry,
-		MaxResponseSize:  c.config.MaxResponseSize,
-		Proxy:            c.config.Proxy,
-		CustomHeaders:    c.config.CustomHeaders,
-		DisableRedirects: c.config.DisableRedirects,
-	})
+	// External plugins from the config file
+	if err := c.openPlugins(); err != nil {
+		return err
+	}
+
+	// Cookie jar shared by both fetchers so a session carries across them
+	c.jar = cookies.NewJar()
+	if c.config.CookieFile != "" {
+		if _, err := cookies.LoadFile(c.config.CookieFile, c.jar); err != nil {
+			return fmt.Errorf("load cookies: %w", err)
+		}
+	}
+
+	// HAR recorder is fed directly by the fetchers, so it must exist first
+	var recorder fetcher.Recorder
+	if c.config.HAROutput != "" {
+		hw, err := output.NewHARWriter(c.config.HAROutput, c.config.HARMaxBodySize)
+		if err != nil {
+			return fmt.Errorf("create HAR output: %w", err)
+		}
+		recorder = hw
+		c.writers = append(c.writers, hw)
+	}
+
+	// On-disk cache lets recrawls revalidate instead of refetching
+	var cache *fetcher.HTTPCache
+	if c.config.HTTPCacheDir != "" {
+		if cache, err = fetcher.NewHTTPCache(c.config.HTTPCacheDir); err != nil {
+			return fmt.Errorf("open HTTP cache: %w", err)
+		}
+	}
+
+	// Initialize HTTP fetcher; a custom fetcher takes its place
+	if c.replay != nil {
+		c.httpFetch = c.replay
+	} else if c.custom.fetcher != nil {
+		c.httpFetch = c.custom.fetcher
+	} else {
+		c.httpFetch = fetcher.NewHTTPFetcher(fetcher.HTTPFetcherConfig{
+			MaxDepth:         c.config.MaxDepth,
+			Parallelism:      c.config.Parallelism,
+			RateLimit:        c.config.RateLimit,
+			UserAgent:        c.config.UserAgent,
+			AllowExternal:    c.config.AllowExternal,
+			RespectRobots:    c.config.RespectRobots,
+			AllowedDomain:    domain,
+			Timeout:          c.config.Timeout,
+			Retry:            c.config.Retry,
+			MaxResponseSize:  c.config.MaxResponseSize,
+			Proxy:            c.config.Proxy,
+			CustomHeaders:    c.config.CustomHeaders,
+			DisableRedirects: c.config.DisableRedirects,
+			CookieJar:        c.jar,
+			Recorder:         recorder,
+			Cache:            cache,
+		})
+	}
 
 	// Initialize browser fetcher if needed
-	if c.config.FetcherMode == FetcherBrowser || c.config.FetcherMode == FetcherAuto {
+	needBrowser := c.config.FetcherMode == FetcherBrowser || c.config.FetcherMode == FetcherAuto
+	if c.config.LoginURL != "" && c.config.LoginVia == FetcherBrowser {
+		needBrowser = true
+	}
+	if needBrowser {
 		bf, err := fetcher.NewBrowserFetcher(fetcher.BrowserFetcherConfig{
 			Timeout:     c.config.BrowserTimeout,
 			PageTimeout: c.config.PageTimeout,
 			UserAgent:   c.config.UserAgent,
 			Headless:    true,
+			Interaction: fetcher.InteractionConfig{
+				Enabled:    c.config.BrowserInteract,
+				MaxScrolls: c.config.MaxScrolls,
+				MaxClicks:  c.config.MaxClicks,
+			},
+			Capture: fetcher.CaptureConfig{
+				Dir:         c.config.CaptureDir,
+				Screenshots: c.config.Screenshots,
+				PDF:         c.config.PDF,
+				Quality:     c.config.ScreenshotQuality,
+				MaxHeight:   c.config.ScreenshotMaxHeight,
+			},
+			CookieJar: c.jar,
+			Recorder:  recorder,
 		})
 		if err != nil {
 			c.emit(plugin.CrawlEvent{
@@ -107,12 +226,142 @@ func (c *Crawler) Init() error {
 		}
 	}
 
+	// Log in before crawling if credentials were configured
+	if c.config.LoginURL != "" {
+		via, ok := c.httpFetch.(loginer)
+		if !ok {
+			return fmt.Errorf("login is not supported by the %s fetcher", c.httpFetch.Name())
+		}
+		if c.config.LoginVia == FetcherBrowser {
+			if c.browFetch == nil {
+				return fmt.Errorf("browser login requested but the browser fetcher is unavailable")
+			}
+			via = c.browFetch.(loginer)
+		}
+		s, err := newSession(c.config, via)
+		if err != nil {
+			return err
+		}
+		if err := s.authenticate(); err != nil {
+			return fmt.Errorf("login failed: %w", err)
+		}
+		c.session = s
+	}
+
 	// Initialize extractors
 	c.extractors = extractor.NewRegistry()
 
-	// Initialize output writer only if saving is requested
+	secretsCfg := extractor.SecretsConfig{
+		RulesFile: c.config.SecretRules,
+		Redact:    c.config.Redact,
+	}
+	if c.config.ScanScripts {
+		secretsCfg.FetchScript = c.scriptFetcher()
+		secretsCfg.InScope = c.inScope
+	}
+	secrets, err := extractor.NewSecretsExtractor(secretsCfg)
+	if err != nil {
+		return err
+	}
+	c.extractors.Register(secrets)
+
+	if c.config.SocialPlatforms != "" {
+		platforms, err := extractor.LoadSocialPlatforms(c.config.SocialPlatforms)
+		if err != nil {
+			return err
+		}
+		social := extractor.NewSocialExtractor()
+		if err := social.AddPlatforms(platforms); err != nil {
+			return fmt.Errorf("%s: %w", c.config.SocialPlatforms, err)
+		}
+		c.extractors.Replace(social)
+	}
+
+	if c.config.SecurityAudit {
+		c.extractors.Register(extractor.NewSecurityExtractor())
+	}
+	if c.config.A11yAudit {
+		c.extractors.Register(extractor.NewAccessibilityExtractor())
+	}
+
+	if c.config.ExtractRules != "" {
+		rules, err := extractor.NewRulesExtractor(c.config.ExtractRules)
+		if err != nil {
+			return err
+		}
+		c.extractors.Register(rules)
+	}
+
+	for _, ext := range c.custom.extractors {
+		if c.extractors.Has(ext.Name()) {
+			return fmt.Errorf("extractor %s is already registered", ext.Name())
+		}
+		c.extractors.Register(ext)
+	}
+
+	if err := c.selectExtractors(); err != nil {
+		return err
+	}
+	c.extractors.Timeout = c.config.ExtractorTimeout
+
+	if c.config.CheckLinks {
+		c.checker = linkcheck.New(linkcheck.Config{
+			Concurrency:   c.config.CheckParallel,
+			PerHost:       c.config.CheckPerHost,
+			Timeout:       c.config.Timeout,
+			UserAgent:     c.config.UserAgent,
+			Proxy:         c.config.Proxy,
+			CustomHeaders: c.config.CustomHeaders,
+			CookieJar:     c.jar,
+		})
+	}
+
+	if c.config.NearDupThreshold > 0 {
+		c.dupes = dedup.NewIndex(c.config.NearDupThreshold)
+	}
+
+	if c.config.WARCOutput != "" {
+		ww, err := output.NewWARCWriter(c.config.WARCOutput, c.config.WARCMaxSize)
+		if err != nil {
+			return fmt.Errorf("create WARC output: %w", err)
+		}
+		c.writers = append(c.writers, ww)
+	}
+
+	if c.config.JSONLOutput != "" {
+		jw, err := output.NewJSONLWriter(c.config.JSONLOutput)
+		if err != nil {
+			return fmt.Errorf("create JSONL output: %w", err)
+		}
+		c.writers = append(c.writers, jw)
+	}
+
+	if c.config.GraphOutput != "" {
+		c.writers = append(c.writers, output.NewGraphWriter(c.config.GraphOutput, c.normalize))
+	}
+
+	c.writers = append(c.writers, c.custom.writers...)
+
+	if c.config.Baseline != "" {
+		b, err := diff.Load(c.config.Baseline)
+		if err != nil {
+			return fmt.Errorf("load baseline: %w", err)
+		}
+		c.baseline = b
+		c.current = diff.NewSnapshot()
+	}
+
+	if c.config.SEOReport != "" {
+		// Titles, descriptions, headings and canonicals all come from it
+		if !c.extractors.Has("metadata") {
+			return fmt.Errorf("the SEO report needs the metadata extractor, which is deselected")
+		}
+		c.seo = seo.New(c.normalize)
+	}
+
+	// Initialize text output only if saving is requested
 	if c.config.SaveOutput {
-		c.writer = output.NewTextWriter(c.config.OutputPath)
+		c.writers = append(c.writers, output.NewTextWriter(c.config.OutputPath))
 	}
 
 	return nil
@@ -133,13 +382,22 @@ func (c *Crawler) Run() error {
 
 	// Worker pool
 	var wg sync.WaitGroup
+	var active atomic.Int32
 	sem := make(chan struct{}, c.config.Parallelism)
 
 	for {
+		// Read the worker count before the queue: a worker enqueues its
+		// links before it stops counting as active, so an empty queue seen
+		// after an idle count really is the end of the frontier
+		idle := active.Load() == 0
 		item, ok := c.dequeue()
 		if !ok {
-			// Wait for in-flight workers before checking again
-			break
+			// In-flight workers may still enqueue links; only stop once idle
+			if idle || c.isStopped() {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+			continue
 		}
 
 		if c.isStopped() {
@@ -148,8 +406,10 @@ func (c *Crawler) Run() error {
 
 		sem <- struct{}{}
 		wg.Add(1)
+		active.Add(1)
 		go func(item queueItem) {
 			defer wg.Done()
+			defer active.Add(-1)
 			defer func() { <-sem }()
 
 			c.processURL(item)
@@ -158,14 +418,52 @@ func (c *Crawler) Run() error {
 
 	wg.Wait()
 
+	if c.checker != nil && !c.isStopped() {
+		c.checkLinks()
+	}
+
+	if c.config.SaveCookies != "" {
+		if err := cookies.SaveFile(c.config.SaveCookies, c.jar); err != nil {
+			c.emit(plugin.CrawlEvent{
+				Type:    plugin.EventPageError,
+				Error:   err,
+				Message: "Failed to save cookies: " + err.Error(),
+			})
+		}
+	}
+
+	if c.baseline != nil {
+		c.report = diff.Compare(c.baseline, c.current)
+		if c.config.DiffOutput != "" {
+			if err := c.writeReport(); err != nil {
+				c.emit(plugin.CrawlEvent{
+					Type:    plugin.EventPageError,
+					Error:   err,
+					Message: "Failed to write diff report: " + err.Error(),
+				})
+			}
+		}
+	}
+
+	if c.seo != nil {
+		c.seoReport = c.seo.Report()
+		if err := c.writeSEOReport(); err != nil {
+			c.emit(plugin.CrawlEvent{
+				Type:    plugin.EventPageError,
+				Error:   err,
+				Message: "Failed to write SEO report: " + err.Error(),
+			})
+		}
+	}
+
 	// Finalize
-	if c.writer != nil {
-		summary := c.buildSummary()
-		if err := c.writer.Finalize(summary); err != nil {
+	c.summary = c.buildSummary()
+	for _, w := range c.writers {
+		if err := w.Finalize(c.summary); err != nil {
 			c.emit(plugin.CrawlEvent{
 				Type:    plugin.EventPageError,
 				Error:   err,
-				Message: "Failed to write output: " + err.Error(),
+				Message: fmt.Sprintf("Failed to write %s output: %v", w.Name(), err),
 			})
 		}
 	}
@@ -185,6 +483,9 @@ func (c *Crawler) Stop() {
 	c.stopMu.Lock()
 	defer c.stopMu.Unlock()
 	c.stopped = true
+	if c.cancel != nil {
+		c.cancel()
+	}
 }
 
 func (c *Crawler) isStopped() bool {
@@ -204,7 +505,28 @@ func (c *Crawler) processURL(item queueItem) {
 	fetchr := c.chooseFetcher(item.url)
 
 	// Fetch the page
+	var generation int
+	if c.session != nil {
+		generation = c.session.current()
+	}
 	pageData, err := fetchr.Fetch(item.url, item.depth)
+
+	// Session expired mid-crawl: log in again and retry once
+	if c.session != nil && c.session.expired(pageData) {
+		if authErr := c.session.reauthenticate(generation); authErr != nil {
+			c.emit(plugin.CrawlEvent{
+				Type:    plugin.EventPageError,
+				URL:     item.url,
+				Error:   authErr,
+				Message: fmt.Sprintf("Re-authentication failed: %v", authErr),
+			})
+		} else {
+			pageData, err = fetchr.Fetch(item.url, item.depth)
+		}
+	}
+
+	c.recordCache(pageData)
+
 	if err != nil {
 		c.statsMu.Lock()
 		c.stats.PagesErrored++
@@ -216,26 +538,74 @@ func (c *Crawler) processURL(item queueItem) {
 			Error:   err,
 			Message: fmt.Sprintf("Error fetching %s: %v", item.url, err),
 		})
+
+		// The server did answer: keep the page, without extraction, so
+		// status changes reach the outputs, the diff and the SEO audit
+		if pageData != nil && pageData.StatusCode != 0 {
+			if pageData.Error == "" {
+				pageData.Error = err.Error()
+			}
+			c.record(&plugin.CrawlResult{Page: pageData}, nil)
+		}
 		return
 	}
 
+	// Fingerprint the content and look for an earlier copy of it
+	if pageData.RawHTML != "" {
+		sum := sha256.Sum256([]byte(pageData.RawHTML))
+		pageData.ContentHash = hex.EncodeToString(sum[:])
+
+		if c.dupes != nil {
+			fp := dedup.Compute(pageData.ContentHash, pageData.RawHTML)
+			pageData.SimHash = fp.SimHashHex()
+			pageData.DuplicateOf, pageData.Similarity = c.dupes.Check(item.url, fp)
+		}
+	}
+
 	// Run all extractors
-	items, _ := c.extractors.ExtractAll(pageData)
+	items, errs := c.extractors.ExtractAll(pageData)
+	for _, err := range errs {
+		c.emit(plugin.CrawlEvent{
+			Type:    plugin.EventExtractorError,
+			URL:     item.url,
+			Error:   err,
+			Message: err.Error(),
+		})
+	}
+	discovered := items
+	if c.hideLinks {
+		items = withoutLinks(items)
+	}
 
 	result := &plugin.CrawlResult{
 		Page:           pageData,
 		ExtractedItems: items,
 	}
 
-	// Write result to output (if saving enabled)
-	if c.writer != nil {
-		_ = c.writer.WriteResult(result)
+	c.record(result, discovered)
+	if c.checker != nil {
+		for _, extracted := range items {
+			if extracted.Type != "link" && extracted.Type != "asset" {
+				continue
+			}
+			// Requesting a logout link would end the session
+			if c.session != nil && c.session.isLogout(extracted.Value) {
+				continue
+			}
+			c.checker.Add(extracted.Value, item.url)
+		}
 	}
 
+	c.recordSecurity(pageData, items)
+	c.recordAccessibility(pageData, items)
+
 	// Update stats
 	c.statsMu.Lock()
 	c.stats.PagesCrawled++
 	c.stats.ItemsExtracted += len(items)
+	if pageData.DuplicateOf != "" {
+		c.stats.PagesDuplicate++
+	}
 	for _, item := range items {
 		c.stats.ItemsByType[item.Type]++
 	}
@@ -253,9 +623,10 @@ func (c *Crawler) processURL(item queueItem) {
 		Stats:  c.getStats(),
 	})
 
-	// Extract links and enqueue them
-	if item.depth < c.config.MaxDepth {
-		for _, extracted := range items {
+	// Extract links and enqueue them; a duplicate's links were already
+	// followed from the page it copies
+	if item.depth < c.config.MaxDepth && pageData.DuplicateOf == "" {
+		for _, extracted := range discovered {
 			if extracted.Type == "link" {
 				linkType := extracted.Metadata["link_type"]
 				if linkType == "internal" || c.config.AllowExternal {
@@ -266,10 +637,232 @@ func (c *Crawler) processURL(item queueItem) {
 	}
 }
 
+// record passes a page's result to the output writers and to the diff
+// snapshot and SEO audit, if enabled. The audit gets every discovered
+// item, links included even when they are hidden from the output.
+func (c *Crawler) record(result *plugin.CrawlResult, discovered []plugin.ExtractedItem) {
+	for _, w := range c.writers {
+		_ = w.WriteResult(result)
+	}
+	if c.current != nil {
+		c.current.Add(result)
+	}
+	if c.seo != nil {
+		c.seo.Add(&plugin.CrawlResult{Page: result.Page, ExtractedItems: discovered})
+	}
+}
+
+// selectExtractors applies --extractors and --skip-extractors. The links
+// extractor drives the crawl itself, so when it is deselected it keeps
+// running and only its items are dropped from the results.
+func (c *Crawler) selectExtractors() error {
+	enable, disable := c.config.Extractors, c.config.ExcludeExtractors
+	if len(enable) == 0 && len(disable) == 0 {
+		return nil
+	}
+
+	hasLinks := func(names []string) bool {
+		for _, n := range names {
+			if n == "links" {
+				return true
+			}
+		}
+		return false
+	}
+	c.hideLinks = (len(enable) > 0 && !hasLinks(enable)) || hasLinks(disable)
+
+	if err := c.extractors.Select(enable, disable); err != nil {
+		return err
+	}
+	if c.hideLinks {
+		c.extractors.Replace(extractor.NewLinksExtractor())
+	}
+	return nil
+}
+
+// withoutLinks returns items minus the "link" items.
+func withoutLinks(items []plugin.ExtractedItem) []plugin.ExtractedItem {
+	out := make([]plugin.ExtractedItem, 0, len(items))
+	for _, item := range items {
+		if item.Type != "link" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// checkLinks verifies every link and asset discovered during the crawl.
+func (c *Crawler) checkLinks() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopMu.Lock()
+	c.cancel = cancel
+	c.stopMu.Unlock()
+	defer cancel()
+
+	c.emit(plugin.CrawlEvent{
+		Type:    plugin.EventProgress,
+		Message: fmt.Sprintf("Checking %d discovered links and assets", c.checker.Len()),
+	})
+
+	results := c.checker.Run(ctx, func(res *plugin.LinkStatus) {
+		c.statsMu.Lock()
+		c.stats.LinksChecked++
+		if res.Broken {
+			c.stats.LinksBroken++
+		}
+		c.statsMu.Unlock()
+
+		c.emit(plugin.CrawlEvent{
+			Type: plugin.EventLinkChecked,
+			URL:  res.URL,
+			Link: res,
+		})
+	})
+	for _, res := range results {
+		c.linkChecks = append(c.linkChecks, *res)
+	}
+}
+
+// recordCache updates the cache hit/miss counters for a fetched page.
+func (c *Crawler) recordCache(page *plugin.PageData) {
+	if page == nil || page.CacheStatus == "" {
+		return
+	}
+
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	if page.CacheStatus == "hit" {
+		c.stats.CacheHits++
+	} else {
+		c.stats.CacheMisses++
+	}
+	c.stats.CacheHitRatio = float64(c.stats.CacheHits) / float64(c.stats.CacheHits+c.stats.CacheMisses)
+}
+
+// recordSecurity adds a page's security findings to its host's rollup.
+// Pages without response headers were not audited and are skipped.
+func (c *Crawler) recordSecurity(page *plugin.PageData, items []plugin.ExtractedItem) {
+	if len(page.Headers) == 0 || !c.extractors.Has("security") {
+		return
+	}
+	host := ""
+	if u, err := url.Parse(page.URL); err == nil {
+		host = u.Hostname()
+	}
+
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	if c.security == nil {
+		c.security = make(map[string]*plugin.HostSecurity)
+	}
+	rollup, ok := c.security[host]
+	if !ok {
+		rollup = &plugin.HostSecurity{
+			Host:       host,
+			BySeverity: make(map[string]int),
+			Issues:     make(map[string]int),
+		}
+		c.security[host] = rollup
+	}
+	rollup.Pages++
+
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if item.Type != "security" {
+			continue
+		}
+		rollup.Findings++
+		rollup.BySeverity[item.Metadata["severity"]]++
+		if issue := item.Metadata["issue"]; !seen[issue] {
+			seen[issue] = true
+			rollup.Issues[issue]++
+		}
+	}
+}
+
+// recordAccessibility adds a page's accessibility findings to the crawl
+// totals. Only HTML pages are checked and counted.
+func (c *Crawler) recordAccessibility(page *plugin.PageData, items []plugin.ExtractedItem) {
+	if page.RenderedHTML == "" && page.RawHTML == "" || !c.extractors.Has("accessibility") {
+		return
+	}
+	if page.ContentType != "" && !strings.Contains(strings.ToLower(page.ContentType), "html") {
+		return
+	}
+
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	if c.a11y.Issues == nil {
+		c.a11y.Issues = make(map[string]int)
+		c.a11y.PagesAffected = make(map[string]int)
+		c.a11y.WCAG = make(map[string]string)
+	}
+	c.a11y.Pages++
+
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if item.Type != "a11y" {
+			continue
+		}
+		issue := item.Metadata["issue"]
+		c.a11y.Findings++
+		c.a11y.Issues[issue]++
+		c.a11y.WCAG[issue] = item.Metadata["wcag"]
+		if !seen[issue] {
+			seen[issue] = true
+			c.a11y.PagesAffected[issue]++
+		}
+	}
+}
+
+// Accessibility returns the crawl's accessibility totals, or nil if no
+// HTML page was checked.
+func (c *Crawler) Accessibility() *plugin.AccessibilitySummary {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	if c.a11y.Pages == 0 {
+		return nil
+	}
+	summary := c.a11y
+	summary.Issues = copyCounts(c.a11y.Issues)
+	summary.PagesAffected = copyCounts(c.a11y.PagesAffected)
+	summary.WCAG = make(map[string]string, len(c.a11y.WCAG))
+	for k, v := range c.a11y.WCAG {
+		summary.WCAG[k] = v
+	}
+	return &summary
+}
+
+// Security returns the per-host security audit rollup, sorted by host.
+func (c *Crawler) Security() []plugin.HostSecurity {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	hosts := make([]plugin.HostSecurity, 0, len(c.security))
+	for _, h := range c.security {
+		host := *h
+		host.BySeverity = copyCounts(h.BySeverity)
+		host.Issues = copyCounts(h.Issues)
+		hosts = append(hosts, host)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Host < hosts[j].Host })
+	return hosts
+}
+
+// copyCounts copies a rollup counter so callers don't share it with a
+// crawl that is still running.
+func copyCounts(m map[string]int) map[string]int {
+	out := make(map[string]int, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
 // chooseFetcher decides whether to use HTTP or browser fetcher.
 func (c *Crawler) chooseFetcher(targetURL string) plugin.Fetcher {
 	switch c.config.FetcherMode {
-	case FetcherHTTP:
+	case FetcherHTTP, FetcherReplay:
 		return c.httpFetch
 	case FetcherBrowser:
 		if c.browFetch != nil {
@@ -296,19 +889,29 @@ func (c *Crawler) chooseFetcher(targetURL string) plugin.Fetcher {
 // enqueue adds a URL to the crawl queue if not already visited.
 func (c *Crawler) enqueue(rawURL string, depth int) {
 	// Normalize the URL
-	normalized := normalizeURL(rawURL)
+	normalized := c.normalize(rawURL)
 	if normalized == "" {
 		return
 	}
 
+	// A replay can only serve what was archived
+	if c.replay != nil && !c.replay.Has(normalized) {
+		return
+	}
+
+	// Never follow links that would end an authenticated session
+	if c.session != nil && c.session.isLogout(normalized) {
+		return
+	}
+
 	c.visitMu.Lock()
 	if c.visited[normalized] {
 		c.visitMu.Unlock()
 		return
 	}
 
-	// Check max pages limit
-	if len(c.visited) >= c.config.MaxPages {
+	// Check max pages limit; 0 means no limit
+	if c.config.MaxPages > 0 && len(c.visited) >= c.config.MaxPages {
 		c.visitMu.Unlock()
 		return
 	}
@@ -330,7 +933,8 @@ func (c *Crawler) enqueue(rawURL string, depth int) {
 	})
 }
 
-// dequeue pops the next URL from the queue.
+// dequeue pops the next URL from the queue: the newest one when crawling
+// depth-first, the oldest one when crawling breadth-first.
 func (c *Crawler) dequeue() (queueItem, bool) {
 	c.queueMu.Lock()
 	defer c.queueMu.Unlock()
@@ -339,6 +943,12 @@ func (c *Crawler) dequeue() (queueItem, bool) {
 		return queueItem{}, false
 	}
 
+	if c.config.Strategy == StrategyDepthFirst {
+		last := len(c.queue) - 1
+		item := c.queue[last]
+		c.queue = c.queue[:last]
+		return item, true
+	}
 	item := c.queue[0]
 	c.queue = c.queue[1:]
 	return item, true
@@ -346,6 +956,12 @@ func (c *Crawler) dequeue() (queueItem, bool) {
 
 // emit sends an event to the event channel (non-blocking).
 func (c *Crawler) emit(event plugin.CrawlEvent) {
+	if c.handler != nil {
+		c.handlerMu.Lock()
+		defer c.handlerMu.Unlock()
+		c.handler(event)
+		return
+	}
 	select {
 	case c.events <- event:
 	default:
@@ -370,7 +986,7 @@ func (c *Crawler) getStats() *plugin.CrawlStats {
 // buildSummary creates the final CrawlSummary.
 func (c *Crawler) buildSummary() *plugin.CrawlSummary {
 	stats := c.getStats()
-	return &plugin.CrawlSummary{
+	summary := &plugin.CrawlSummary{
 		TargetURL:   c.config.TargetURL,
 		StartedAt:   c.startTime,
 		FinishedAt:  time.Now(),
@@ -380,6 +996,61 @@ func (c *Crawler) buildSummary() *plugin.CrawlSummary {
 		TotalItems:  stats.ItemsExtracted,
 		ItemsByType: stats.ItemsByType,
 	}
+	if c.dupes != nil {
+		summary.DuplicateGroups = c.dupes.Groups()
+	}
+	summary.LinkChecks = c.linkChecks
+	summary.Security = c.Security()
+	summary.Accessibility = c.Accessibility()
+	return summary
+}
+
+// Summary returns the crawl summary handed to the output writers. It is
+// available once Run returns.
+func (c *Crawler) Summary() *plugin.CrawlSummary {
+	return c.summary
+}
+
+// Diff returns the changes against the baseline crawl, or nil if no
+// baseline was configured. It is available once Run returns.
+func (c *Crawler) Diff() *diff.Report {
+	return c.report
+}
+
+// writeReport saves the diff report, as JSON when the path ends in .json.
+func (c *Crawler) writeReport() error {
+	f, err := os.Create(c.config.DiffOutput)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if strings.HasSuffix(strings.ToLower(c.config.DiffOutput), ".json") {
+		return c.report.WriteJSON(f)
+	}
+	c.report.WriteText(f)
+	return nil
+}
+
+// SEO returns the site-wide SEO report, or nil if no SEO report was
+// requested. It is available once Run returns.
+func (c *Crawler) SEO() *seo.Report {
+	return c.seoReport
+}
+
+// writeSEOReport saves the SEO report, as JSON when the path ends in .json.
+func (c *Crawler) writeSEOReport() error {
+	f, err := os.Create(c.config.SEOReport)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if strings.HasSuffix(strings.ToLower(c.config.SEOReport), ".json") {
+		return c.seoReport.WriteJSON(f)
+	}
+	c.seoReport.WriteText(f)
+	return nil
 }
 
 // Close releases all resources.
@@ -390,11 +1061,21 @@ func (c *Crawler) Close() error {
 	if c.browFetch != nil {
 		c.browFetch.Close()
 	}
+	c.closePlugins()
 	return nil
 }
 
-// normalizeURL cleans up a URL for deduplication.
-func normalizeURL(rawURL string) string {
+// normalize cleans up a URL for deduplication. Hash routes are kept only
+// when every page goes through the browser fetcher; over HTTP the
+// fragment never reaches the server, so each route would refetch the
+// same document.
+func (c *Crawler) normalize(rawURL string) string {
+	return normalizeURL(rawURL, c.config.FetcherMode == FetcherBrowser && c.browFetch != nil)
+}
+
+// normalizeURL cleans up a URL for deduplication, keeping client-side
+// hash routes if hashRoutes is set.
+func normalizeURL(rawURL string, hashRoutes bool) string {
 	parsed, err := url.Parse(rawURL)
 	if err != nil {
 		return ""
@@ -405,8 +1086,10 @@ func normalizeURL(rawURL string) string {
 		return ""
 	}
 
-	// Remove fragment
-	parsed.Fragment = ""
+	// Remove fragment, unless it is a client-side hash route (#/path, #!/path)
+	if !hashRoutes || (!strings.HasPrefix(parsed.Fragment, "/") && !strings.HasPrefix(parsed.Fragment, "!")) {
+		parsed.Fragment = ""
+	}
 
 	// Remove trailing slash for consistency
 	parsed.Path = strings.TrimRight(parsed.Path, "/")
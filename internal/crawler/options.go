@@ -15,6 +15,7 @@ type CrawlConfig struct {
 	CrawlDuration     time.Duration
 	Strategy          Strategy
 	IgnoreQueryParams bool
+	NearDupThreshold  float64 // 0 disables duplicate detection
 
 	// Request options
 	UserAgent        string
@@ -26,6 +27,9 @@ type CrawlConfig struct {
 	CustomResolvers  []string
 	DisableRedirects bool
 	TLSImpersonate   bool
+	CookieFile       string
+	SaveCookies      string
+	HTTPCacheDir     string
 
 	// Feature flags
 	AllowExternal  bool
@@ -37,18 +41,66 @@ type CrawlConfig struct {
 	FormExtraction bool
 	TechDetect     bool
 	FetcherMode    FetcherMode
+	CheckLinks     bool
+	CheckParallel  int
+	CheckPerHost   int
+	ReplayInput    string
+	ScanScripts    bool
+	SecretRules    string
+	Redact         string
+	SecurityAudit  bool
+	A11yAudit      bool
+
+	// Extractors
+	Extractors        []string      // run only these; empty runs all
+	ExcludeExtractors []string      // never run these
+	ExtractorTimeout  time.Duration // per extractor and page; 0 disables
 
 	// Output
-	OutputPath string
-	SaveOutput bool
-	Silent     bool
-	Verbose    bool
-	NoColor    bool
+	OutputPath     string
+	SaveOutput     bool
+	HAROutput      string
+	HARMaxBodySize int
+	WARCOutput     string
+	WARCMaxSize    int64
+	JSONLOutput    string
+	GraphOutput    string
+	Baseline       string
+	DiffOutput     string
+	SEOReport      string
+	Silent         bool
+	Verbose        bool
+	NoColor        bool
 
 	// Config files
-	ConfigFile  string
-	FormConfig  string
-	FieldConfig string
+	ConfigFile      string
+	FormConfig      string
+	FieldConfig     string
+	ExtractRules    string
+	SocialPlatforms string
+
+	// Browser interaction
+	BrowserInteract bool
+	MaxScrolls      int
+	MaxClicks       int
+
+	// Capture (browser mode)
+	Screenshots         bool
+	PDF                 bool
+	CaptureDir          string
+	ScreenshotQuality   int
+	ScreenshotMaxHeight int
+
+	// Authentication
+	LoginURL       string
+	LoginUserField string
+	LoginPassField string
+	LoginSubmit    string
+	LoginUserEnv   string
+	LoginPassEnv   string
+	LoginVia       FetcherMode
+	LoginCheck     string
+	LogoutPattern  string
 
 	// Internal
 	BrowserTimeout time.Duration
@@ -62,6 +114,7 @@ const (
 	FetcherHTTP    FetcherMode = "http"
 	FetcherBrowser FetcherMode = "browser"
 	FetcherAuto    FetcherMode = "auto"
+	FetcherReplay  FetcherMode = "replay"
 )
 
 // Strategy defines the crawl order.
@@ -75,21 +128,37 @@ const (
 // DefaultConfig returns a sensible default configuration.
 func DefaultConfig() *CrawlConfig {
 	return &CrawlConfig{
-		MaxDepth:        3,
-		MaxPages:        500,
-		Parallelism:     5,
-		RateLimit:       200 * time.Millisecond,
-		Strategy:        StrategyDepthFirst,
-		UserAgent:       "WebCrawler/1.0",
-		Timeout:         10 * time.Second,
-		Retry:           1,
-		MaxResponseSize: 4194304, // 4MB
-		AllowExternal:   false,
-		RespectRobots:   true,
-		FetcherMode:     FetcherHTTP,
-		SaveOutput:      false,
-		OutputPath:      "crawl_results.json",
-		BrowserTimeout:  30 * time.Second,
-		PageTimeout:     15 * time.Second,
+		MaxDepth:            3,
+		MaxPages:            500,
+		Parallelism:         5,
+		RateLimit:           200 * time.Millisecond,
+		Strategy:            StrategyBreadthFirst,
+		UserAgent:           "WebCrawler/1.0",
+		Timeout:             10 * time.Second,
+		Retry:               1,
+		MaxResponseSize:     4194304, // 4MB
+		AllowExternal:       false,
+		RespectRobots:       true,
+		FetcherMode:         FetcherHTTP,
+		CheckParallel:       10,
+		CheckPerHost:        2,
+		Redact:              "partial",
+		ExtractorTimeout:    30 * time.Second,
+		SaveOutput:          false,
+		OutputPath:          "crawl_results.json",
+		HARMaxBodySize:      1048576,    // 1MB
+		WARCMaxSize:         1073741824, // 1GB
+		BrowserTimeout:      30 * time.Second,
+		PageTimeout:         15 * time.Second,
+		MaxScrolls:          10,
+		MaxClicks:           20,
+		CaptureDir:          "captures",
+		ScreenshotMaxHeight: 10000,
+		LoginUserField:      "username",
+		LoginPassField:      "password",
+		LoginUserEnv:        "GOFANG_USER",
+		LoginPassEnv:        "GOFANG_PASS",
+		LoginVia:            FetcherHTTP,
+		LogoutPattern:       `(?i)(log|sign)[-_]?(out|off)`,
 	}
 }
@@ -0,0 +1,77 @@
+package crawler
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ramkansal/gofang/internal/pluginhost"
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// customPlugins are the plugins added on top of the built-in ones,
+// either registered by an embedding program or declared in the config
+// file, sorted by kind.
+type customPlugins struct {
+	fetcher    plugin.Fetcher
+	extractors []plugin.Extractor
+	writers    []plugin.OutputWriter
+}
+
+// RegisterExtractor adds an extractor that runs after the built-in ones.
+// Its name must not clash with another extractor's. It must be called
+// before Init.
+func (c *Crawler) RegisterExtractor(ext plugin.Extractor) {
+	c.custom.extractors = append(c.custom.extractors, ext)
+}
+
+// RegisterWriter adds an output writer. It must be called before Init.
+func (c *Crawler) RegisterWriter(w plugin.OutputWriter) {
+	c.custom.writers = append(c.custom.writers, w)
+}
+
+// SetFetcher replaces the HTTP fetcher with f. It must be called before
+// Init.
+func (c *Crawler) SetFetcher(f plugin.Fetcher) {
+	c.custom.fetcher = f
+}
+
+// openPlugins starts every plugin declared in the config file. Each
+// process is tracked so Close can stop it even if the crawl never
+// finalizes.
+func (c *Crawler) openPlugins() error {
+	if c.config.ConfigFile == "" {
+		return nil
+	}
+	specs, err := pluginhost.LoadConfig(c.config.ConfigFile)
+	if err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		if spec.Kind == pluginhost.KindFetcher && (c.custom.fetcher != nil || c.replay != nil) {
+			c.closePlugins()
+			return fmt.Errorf("fetcher plugin %s: a fetcher is already set", spec.Name)
+		}
+		p, err := pluginhost.Open(spec)
+		if err != nil {
+			c.closePlugins()
+			return fmt.Errorf("open plugin: %w", err)
+		}
+		c.plugins = append(c.plugins, p.(io.Closer))
+		switch p := p.(type) {
+		case plugin.Fetcher:
+			c.custom.fetcher = p
+		case plugin.Extractor:
+			c.custom.extractors = append(c.custom.extractors, p)
+		case plugin.OutputWriter:
+			c.custom.writers = append(c.custom.writers, p)
+		}
+	}
+	return nil
+}
+
+func (c *Crawler) closePlugins() {
+	for _, p := range c.plugins {
+		p.Close()
+	}
+	c.plugins = nil
+}
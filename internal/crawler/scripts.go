@@ -0,0 +1,38 @@
+package crawler
+
+import (
+	"net/url"
+	"strings"
+)
+
+// scriptFetcher returns the function the secrets extractor uses to download
+// external scripts. It goes through the HTTP fetcher (or the replay
+// archive), so robots.txt, the rate limit, proxy, headers, cookies and the
+// response size limit apply as they do to pages, while the scripts stay out
+// of the crawl frontier.
+func (c *Crawler) scriptFetcher() func(string) (string, error) {
+	return func(target string) (string, error) {
+		page, err := c.httpFetch.Fetch(target, 0)
+		if err != nil {
+			return "", err
+		}
+		return page.RawHTML, nil
+	}
+}
+
+// inScope reports whether target is on the crawl's host, or any host when
+// external links are allowed.
+func (c *Crawler) inScope(target string) bool {
+	if c.config.AllowExternal {
+		return true
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	seed, err := url.Parse(c.config.TargetURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Hostname(), seed.Hostname())
+}
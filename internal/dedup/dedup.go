@@ -0,0 +1,271 @@
+// Package dedup fingerprints page content and detects exact and
+// near-duplicate pages (mirrors, paginated copies, print views).
+package dedup
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	shingleSize = 3  // words per shingle
+	numHashes   = 64 // MinHash signature length
+	bandRows    = 4  // LSH rows per band; numHashes/bandRows bands
+)
+
+// Fingerprint summarises a page's content.
+type Fingerprint struct {
+	Hash    string   // exact hash of the raw body
+	SimHash uint64   // 64-bit SimHash of the visible text
+	MinHash []uint64 // MinHash signature of the visible text shingles
+}
+
+// Compute fingerprints a page from its exact body hash and HTML.
+func Compute(hash, html string) *Fingerprint {
+	fp := &Fingerprint{Hash: hash}
+	shingles := shingle(VisibleText(html))
+	if len(shingles) == 0 {
+		return fp
+	}
+	fp.SimHash = simHash(shingles)
+	fp.MinHash = minHash(shingles)
+	return fp
+}
+
+// SimHashHex formats the SimHash for output.
+func (fp *Fingerprint) SimHashHex() string {
+	if fp.SimHash == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%016x", fp.SimHash)
+}
+
+// Similarity estimates the Jaccard similarity of two pages' shingle sets.
+func Similarity(a, b *Fingerprint) float64 {
+	if a.Hash != "" && a.Hash == b.Hash {
+		return 1
+	}
+	if len(a.MinHash) != numHashes || len(b.MinHash) != numHashes {
+		return 0
+	}
+	same := 0
+	for i := range a.MinHash {
+		if a.MinHash[i] == b.MinHash[i] {
+			same++
+		}
+	}
+	return float64(same) / numHashes
+}
+
+// HammingDistance returns the number of differing SimHash bits.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// VisibleText returns the lowercased, whitespace-collapsed text a reader
+// would see, without scripts, styles and other non-rendered markup.
+func VisibleText(html string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return ""
+	}
+	doc.Find("script, style, noscript, template, svg, head").Remove()
+	return strings.ToLower(strings.Join(strings.Fields(doc.Text()), " "))
+}
+
+// ---------- Index ----------
+
+// Index remembers the pages seen so far and finds duplicates among them.
+// Only canonical (first-seen) pages are indexed, so groups stay flat.
+type Index struct {
+	threshold float64
+
+	mu        sync.Mutex
+	exact     map[string]string   // body hash -> canonical URL
+	buckets   map[uint64][]string // LSH band key -> canonical URLs
+	pages     map[string]*Fingerprint
+	groups    map[string][]string // canonical URL -> duplicate URLs
+	canonical []string            // canonical URLs in first-seen order
+}
+
+// NewIndex creates an index that treats pages with an estimated similarity
+// of at least threshold (0-1] as near-duplicates.
+func NewIndex(threshold float64) *Index {
+	return &Index{
+		threshold: threshold,
+		exact:     make(map[string]string),
+		buckets:   make(map[uint64][]string),
+		pages:     make(map[string]*Fingerprint),
+		groups:    make(map[string][]string),
+	}
+}
+
+// Check records url and returns the earlier page it duplicates together
+// with their similarity, or "" if it is new content.
+func (x *Index) Check(url string, fp *Fingerprint) (string, float64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if fp.Hash != "" {
+		if orig, ok := x.exact[fp.Hash]; ok && orig != url {
+			x.groups[orig] = append(x.groups[orig], url)
+			return orig, 1
+		}
+	}
+
+	// Candidates share at least one LSH band; confirm with the estimate
+	best, bestSim := "", 0.0
+	keys := bandKeys(fp.MinHash)
+	for _, key := range keys {
+		for _, cand := range x.buckets[key] {
+			if cand == url {
+				continue
+			}
+			if sim := Similarity(fp, x.pages[cand]); sim > bestSim {
+				best, bestSim = cand, sim
+			}
+		}
+	}
+	if best != "" && bestSim >= x.threshold {
+		x.groups[best] = append(x.groups[best], url)
+		return best, bestSim
+	}
+
+	// New content becomes a canonical page
+	if _, seen := x.pages[url]; !seen {
+		x.pages[url] = fp
+		x.canonical = append(x.canonical, url)
+		if fp.Hash != "" {
+			x.exact[fp.Hash] = url
+		}
+		for _, key := range keys {
+			x.buckets[key] = append(x.buckets[key], url)
+		}
+	}
+	return "", 0
+}
+
+// Groups returns every canonical page that has duplicates, followed by its
+// duplicates, in the order they were crawled.
+func (x *Index) Groups() [][]string {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	var out [][]string
+	for _, url := range x.canonical {
+		if dups := x.groups[url]; len(dups) > 0 {
+			out = append(out, append([]string{url}, dups...))
+		}
+	}
+	return out
+}
+
+// ---------- hashing ----------
+
+// shingle splits text into overlapping word n-grams.
+func shingle(text string) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+	if len(words) < shingleSize {
+		return []string{strings.Join(words, " ")}
+	}
+	out := make([]string, 0, len(words)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		out = append(out, strings.Join(words[i:i+shingleSize], " "))
+	}
+	return out
+}
+
+func hash64(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// simHash computes a 64-bit SimHash where every shingle votes equally.
+func simHash(shingles []string) uint64 {
+	var votes [64]int
+	for _, s := range shingles {
+		h := hash64(s)
+		for i := 0; i < 64; i++ {
+			if h&(1<<uint(i)) != 0 {
+				votes[i]++
+			} else {
+				votes[i]--
+			}
+		}
+	}
+	var out uint64
+	for i, v := range votes {
+		if v > 0 {
+			out |= 1 << uint(i)
+		}
+	}
+	return out
+}
+
+// minHash computes the MinHash signature using numHashes seeded mixes of
+// each shingle's base hash.
+func minHash(shingles []string) []uint64 {
+	sig := make([]uint64, numHashes)
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for _, s := range shingles {
+		base := hash64(s)
+		for i := range sig {
+			if h := mix(base ^ seeds[i]); h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// bandKeys hashes each band of a signature into a bucket key.
+func bandKeys(sig []uint64) []uint64 {
+	if len(sig) != numHashes {
+		return nil
+	}
+	keys := make([]uint64, 0, numHashes/bandRows)
+	buf := make([]byte, 8*bandRows+1)
+	for b := 0; b < numHashes/bandRows; b++ {
+		buf[0] = byte(b)
+		for r := 0; r < bandRows; r++ {
+			binary.LittleEndian.PutUint64(buf[1+8*r:], sig[b*bandRows+r])
+		}
+		h := fnv.New64a()
+		h.Write(buf)
+		keys = append(keys, h.Sum64())
+	}
+	return keys
+}
+
+// mix is the splitmix64 finaliser.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// seeds are fixed so fingerprints are comparable across runs.
+var seeds = func() [numHashes]uint64 {
+	var s [numHashes]uint64
+	x := uint64(0x9e3779b97f4a7c15)
+	for i := range s {
+		x += 0x9e3779b97f4a7c15
+		s[i] = mix(x)
+	}
+	return s
+}()
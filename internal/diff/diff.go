@@ -0,0 +1,242 @@
+// Package diff compares two crawls of the same site and reports what
+// changed between them: pages, status codes, content and extracted items.
+package diff
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// Snapshot is the comparable state of one crawl.
+type Snapshot struct {
+	Pages map[string]*Page
+	Items map[string]map[string]bool // item type -> set of values, site-wide
+	mu    sync.Mutex
+}
+
+// Page is the per-page state kept in a Snapshot.
+type Page struct {
+	URL         string
+	StatusCode  int
+	ContentHash string
+}
+
+// NewSnapshot returns an empty Snapshot.
+func NewSnapshot() *Snapshot {
+	return &Snapshot{
+		Pages: make(map[string]*Page),
+		Items: make(map[string]map[string]bool),
+	}
+}
+
+// Add records a crawl result. It is safe for concurrent use.
+func (s *Snapshot) Add(result *plugin.CrawlResult) {
+	if result == nil || result.Page == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := result.Page
+	s.Pages[p.URL] = &Page{
+		URL:         p.URL,
+		StatusCode:  p.StatusCode,
+		ContentHash: p.ContentHash,
+	}
+	for _, item := range result.ExtractedItems {
+		// Link churn already shows up as added/removed pages
+		if item.Type == "link" {
+			continue
+		}
+		if s.Items[item.Type] == nil {
+			s.Items[item.Type] = make(map[string]bool)
+		}
+		s.Items[item.Type][item.Value] = true
+	}
+}
+
+// Load reads a JSONL file written by the jsonl output writer.
+func Load(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := NewSnapshot()
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			var result plugin.CrawlResult
+			if jerr := json.Unmarshal(line, &result); jerr != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, n, jerr)
+			}
+			s.Add(&result)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Report describes the differences between two snapshots.
+type Report struct {
+	OldPages       int                 `json:"old_pages"`
+	NewPages       int                 `json:"new_pages"`
+	AddedPages     []string            `json:"added_pages"`
+	RemovedPages   []string            `json:"removed_pages"`
+	StatusChanges  []StatusChange      `json:"status_changes"`
+	ContentChanges []string            `json:"content_changes"`
+	AddedItems     map[string][]string `json:"added_items"`
+	RemovedItems   map[string][]string `json:"removed_items"`
+}
+
+// StatusChange is a page whose status code differs between runs.
+type StatusChange struct {
+	URL string `json:"url"`
+	Old int    `json:"old"`
+	New int    `json:"new"`
+}
+
+// Compare reports what changed from old to new.
+func Compare(old, new *Snapshot) *Report {
+	r := &Report{
+		OldPages:       len(old.Pages),
+		NewPages:       len(new.Pages),
+		AddedPages:     []string{},
+		RemovedPages:   []string{},
+		StatusChanges:  []StatusChange{},
+		ContentChanges: []string{},
+		AddedItems:     make(map[string][]string),
+		RemovedItems:   make(map[string][]string),
+	}
+
+	for url, np := range new.Pages {
+		op, ok := old.Pages[url]
+		if !ok {
+			r.AddedPages = append(r.AddedPages, url)
+			continue
+		}
+		if op.StatusCode != np.StatusCode {
+			r.StatusChanges = append(r.StatusChanges, StatusChange{URL: url, Old: op.StatusCode, New: np.StatusCode})
+		}
+		if op.ContentHash != "" && np.ContentHash != "" && op.ContentHash != np.ContentHash {
+			r.ContentChanges = append(r.ContentChanges, url)
+		}
+	}
+	for url := range old.Pages {
+		if _, ok := new.Pages[url]; !ok {
+			r.RemovedPages = append(r.RemovedPages, url)
+		}
+	}
+
+	sort.Strings(r.AddedPages)
+	sort.Strings(r.RemovedPages)
+	sort.Strings(r.ContentChanges)
+	sort.Slice(r.StatusChanges, func(i, j int) bool { return r.StatusChanges[i].URL < r.StatusChanges[j].URL })
+
+	for typ, values := range new.Items {
+		if added := missingFrom(values, old.Items[typ]); len(added) > 0 {
+			r.AddedItems[typ] = added
+		}
+	}
+	for typ, values := range old.Items {
+		if removed := missingFrom(values, new.Items[typ]); len(removed) > 0 {
+			r.RemovedItems[typ] = removed
+		}
+	}
+
+	return r
+}
+
+// Empty reports whether nothing changed.
+func (r *Report) Empty() bool {
+	return len(r.AddedPages) == 0 && len(r.RemovedPages) == 0 &&
+		len(r.StatusChanges) == 0 && len(r.ContentChanges) == 0 &&
+		len(r.AddedItems) == 0 && len(r.RemovedItems) == 0
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
+}
+
+// WriteText writes a human-readable report.
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "  Pages:  %d -> %d (+%d new, -%d removed, %d status changes, %d content changes)\n",
+		r.OldPages, r.NewPages, len(r.AddedPages), len(r.RemovedPages), len(r.StatusChanges), len(r.ContentChanges))
+
+	if r.Empty() {
+		fmt.Fprintf(w, "  No changes\n")
+		return
+	}
+
+	for _, u := range r.AddedPages {
+		fmt.Fprintf(w, "    + %s\n", u)
+	}
+	for _, u := range r.RemovedPages {
+		fmt.Fprintf(w, "    - %s\n", u)
+	}
+	for _, c := range r.StatusChanges {
+		fmt.Fprintf(w, "    ~ %s (%d -> %d)\n", c.URL, c.Old, c.New)
+	}
+	for _, u := range r.ContentChanges {
+		fmt.Fprintf(w, "    * %s (content changed)\n", u)
+	}
+
+	types := make(map[string]bool)
+	for t := range r.AddedItems {
+		types[t] = true
+	}
+	for t := range r.RemovedItems {
+		types[t] = true
+	}
+	if len(types) == 0 {
+		return
+	}
+	sorted := make([]string, 0, len(types))
+	for t := range types {
+		sorted = append(sorted, t)
+	}
+	sort.Strings(sorted)
+
+	fmt.Fprintf(w, "  Items:\n")
+	for _, t := range sorted {
+		fmt.Fprintf(w, "    %s: +%d -%d\n", t, len(r.AddedItems[t]), len(r.RemovedItems[t]))
+		for _, v := range r.AddedItems[t] {
+			fmt.Fprintf(w, "      + %s\n", v)
+		}
+		for _, v := range r.RemovedItems[t] {
+			fmt.Fprintf(w, "      - %s\n", v)
+		}
+	}
+}
+
+// missingFrom returns the sorted values of a that are not in b.
+func missingFrom(a, b map[string]bool) []string {
+	var out []string
+	for v := range a {
+		if !b[v] {
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,206 @@
+package extractor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ramkansal/gofang/pkg/plugin"
+	"golang.org/x/net/html"
+)
+
+// wcagCriteria maps each accessibility issue to the WCAG 2.x success
+// criterion it violates.
+var wcagCriteria = map[string]string{
+	"img_alt_missing":     "1.1.1",
+	"input_label_missing": "1.3.1",
+	"lang_missing":        "3.1.1",
+	"heading_skip":        "1.3.1",
+	"empty_link":          "2.4.4",
+	"empty_button":        "4.1.2",
+	// 4.1.1 Parsing, which covered duplicate IDs, is obsolete as of WCAG
+	// 2.2; duplicates still break the label and ARIA references that
+	// convey relationships
+	"duplicate_id": "1.3.1",
+}
+
+// AccessibilityExtractor checks a page for common WCAG failures: images
+// without alt text, unlabelled form controls, a missing document language,
+// skipped heading levels, links and buttons without an accessible name and
+// duplicate IDs. Each failure becomes an "a11y" item.
+type AccessibilityExtractor struct{}
+
+func NewAccessibilityExtractor() *AccessibilityExtractor { return &AccessibilityExtractor{} }
+
+func (e *AccessibilityExtractor) Name() string { return "accessibility" }
+
+func (e *AccessibilityExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem, error) {
+	if !isHTMLPage(page) {
+		return nil, nil
+	}
+	doc, err := document(page)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []plugin.ExtractedItem
+	add := func(issue, detail string, n *html.Node) {
+		meta := map[string]string{
+			"issue":  issue,
+			"wcag":   wcagCriteria[issue],
+			"detail": detail,
+		}
+		if n != nil {
+			meta["element"] = elementSnippet(n)
+		}
+		items = append(items, plugin.ExtractedItem{
+			Type:      "a11y",
+			Value:     fmt.Sprintf("[WCAG %s] %s", wcagCriteria[issue], detail),
+			SourceURL: page.URL,
+			Metadata:  meta,
+		})
+	}
+
+	// Document language
+	if lang, _ := doc.Find("html").First().Attr("lang"); strings.TrimSpace(lang) == "" {
+		add("lang_missing", "<html> has no lang attribute", nil)
+	}
+
+	// Images need alt text; alt="" marks a decorative image
+	doc.Find("img, input[type=image], area[href]").Each(func(_ int, s *goquery.Selection) {
+		if _, ok := s.Attr("alt"); ok || hasAccessibleLabel(s) || isHiddenFromAT(s) {
+			return
+		}
+		add("img_alt_missing", goquery.NodeName(s)+" without alt text", s.Get(0))
+	})
+
+	// Form controls need a label
+	labelled := make(map[string]bool)
+	doc.Find("label[for]").Each(func(_ int, s *goquery.Selection) {
+		labelled[s.AttrOr("for", "")] = true
+	})
+	doc.Find("input, select, textarea").Each(func(_ int, s *goquery.Selection) {
+		if goquery.NodeName(s) == "input" {
+			switch strings.ToLower(s.AttrOr("type", "text")) {
+			case "hidden", "submit", "reset", "button", "image":
+				return
+			}
+		}
+		if id := s.AttrOr("id", ""); id != "" && labelled[id] {
+			return
+		}
+		if s.ParentsFiltered("label").Length() > 0 || hasAccessibleLabel(s) || isHiddenFromAT(s) {
+			return
+		}
+		add("input_label_missing", goquery.NodeName(s)+" without a label", s.Get(0))
+	})
+
+	// Heading levels must not skip (h2 followed by h4)
+	prev := 0
+	doc.Find("h1, h2, h3, h4, h5, h6").Each(func(_ int, s *goquery.Selection) {
+		level := int(goquery.NodeName(s)[1] - '0')
+		if prev > 0 && level > prev+1 {
+			add("heading_skip", fmt.Sprintf("heading level skipped: h%d -> h%d", prev, level), s.Get(0))
+		}
+		prev = level
+	})
+
+	// Links and buttons need an accessible name
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		if !isHiddenFromAT(s) && accessibleName(s) == "" {
+			add("empty_link", "link without text or accessible name", s.Get(0))
+		}
+	})
+	doc.Find(`button, [role="button"]:not(a), input[type=submit], input[type=button], input[type=reset]`).Each(func(_ int, s *goquery.Selection) {
+		if isHiddenFromAT(s) {
+			return
+		}
+		name := accessibleName(s)
+		if goquery.NodeName(s) == "input" {
+			typ := strings.ToLower(s.AttrOr("type", ""))
+			// Submit and reset buttons get a default label from the browser
+			if _, ok := s.Attr("value"); ok || typ == "submit" || typ == "reset" {
+				return
+			}
+		}
+		if name == "" {
+			add("empty_button", "button without text or accessible name", s.Get(0))
+		}
+	})
+
+	// IDs must be unique
+	ids := make(map[string]int)
+	doc.Find("[id]").Each(func(_ int, s *goquery.Selection) {
+		id := strings.TrimSpace(s.AttrOr("id", ""))
+		if id == "" {
+			return
+		}
+		ids[id]++
+		if ids[id] == 2 {
+			add("duplicate_id", fmt.Sprintf("duplicate id %q", id), s.Get(0))
+		}
+	})
+
+	return items, nil
+}
+
+// isHTMLPage reports whether page holds an HTML document. Pages without a
+// Content-Type are assumed to be HTML.
+func isHTMLPage(page *plugin.PageData) bool {
+	if page.RenderedHTML == "" && page.RawHTML == "" {
+		return false
+	}
+	return page.ContentType == "" || strings.Contains(strings.ToLower(page.ContentType), "html")
+}
+
+// hasAccessibleLabel reports whether the element is named through ARIA or
+// a title.
+func hasAccessibleLabel(s *goquery.Selection) bool {
+	for _, a := range []string{"aria-label", "aria-labelledby", "title"} {
+		if strings.TrimSpace(s.AttrOr(a, "")) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// accessibleName approximates the accessible name of a link or button:
+// ARIA label or title, else its text, else the alt text of images inside.
+func accessibleName(s *goquery.Selection) string {
+	if hasAccessibleLabel(s) {
+		return "labelled"
+	}
+	if text := strings.TrimSpace(s.Text()); text != "" {
+		return text
+	}
+	name := ""
+	s.Find("img[alt], svg[aria-label]").EachWithBreak(func(_ int, img *goquery.Selection) bool {
+		name = strings.TrimSpace(img.AttrOr("alt", img.AttrOr("aria-label", "")))
+		return name == ""
+	})
+	return name
+}
+
+// isHiddenFromAT reports whether assistive technology ignores the element.
+func isHiddenFromAT(s *goquery.Selection) bool {
+	if s.AttrOr("aria-hidden", "") == "true" {
+		return true
+	}
+	switch s.AttrOr("role", "") {
+	case "presentation", "none":
+		return true
+	}
+	return false
+}
+
+// elementSnippet renders an element's start tag for locating it in the
+// source.
+func elementSnippet(n *html.Node) string {
+	var b strings.Builder
+	b.WriteString("<" + n.Data)
+	for _, a := range n.Attr {
+		fmt.Fprintf(&b, " %s=%q", a.Key, a.Val)
+	}
+	b.WriteString(">")
+	return truncate(b.String(), 200)
+}
@@ -25,7 +25,7 @@ func (e *AssetsExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem
 		return nil, nil
 	}
 
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	doc, err := document(page)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,72 @@
+package extractor
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ramkansal/gofang/pkg/plugin"
+	"golang.org/x/net/html"
+)
+
+// documents holds the parsed HTML of the pages the registry is currently
+// running extractors on, keyed by *plugin.PageData, so each page is parsed
+// once however many extractors read it.
+var documents sync.Map
+
+type parsedDoc struct {
+	once sync.Once
+	doc  *goquery.Document
+	err  error
+}
+
+func (d *parsedDoc) get(page *plugin.PageData) (*goquery.Document, error) {
+	d.once.Do(func() { d.doc, d.err = parseHTML(page) })
+	return d.doc, d.err
+}
+
+// document returns the page's HTML (RenderedHTML, else RawHTML) parsed
+// into a goquery document, or nil, nil for a page without HTML. While the
+// registry runs extractors on the page the document is shared between
+// them, so callers must not modify it.
+func document(page *plugin.PageData) (*goquery.Document, error) {
+	if d, ok := documents.Load(page); ok {
+		return d.(*parsedDoc).get(page)
+	}
+	return parseHTML(page)
+}
+
+func parseHTML(page *plugin.PageData) (*goquery.Document, error) {
+	src := page.RenderedHTML
+	if src == "" {
+		src = page.RawHTML
+	}
+	if src == "" {
+		return nil, nil
+	}
+	return goquery.NewDocumentFromReader(strings.NewReader(src))
+}
+
+// visibleText returns the document's text outside script, style, noscript
+// and template elements, with a space between text nodes so adjacent
+// cells don't run together. It leaves the document unmodified.
+func visibleText(doc *goquery.Document) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style" || n.Data == "noscript" || n.Data == "template"):
+			return
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range doc.Nodes {
+		walk(n)
+	}
+	return b.String()
+}
@@ -1,32 +1,44 @@
 package extractor
 
 import (
+	"encoding/hex"
+	"html"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/ramkansal/gofang/pkg/plugin"
 )
 
-// EmailsExtractor extracts email addresses from page content and mailto: links.
+// EmailsExtractor extracts email addresses from page content and mailto:
+// links. It decodes HTML entities, "name [at] host [dot] com" style
+// obfuscation and Cloudflare email protection, and drops addresses whose
+// domain could not receive mail.
 type EmailsExtractor struct {
-	pattern *regexp.Regexp
+	pattern    *regexp.Regexp
+	obfuscated *regexp.Regexp
+	dotWord    *regexp.Regexp
 }
 
 func NewEmailsExtractor() *EmailsExtractor {
 	return &EmailsExtractor{
 		pattern: regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`),
+		// user [at] host [dot] com, user(at)host(dot)com, user {at} host.com
+		obfuscated: regexp.MustCompile(`(?i)([a-z0-9._%+\-]+)\s*[\[({<]\s*at\s*[\])}>]\s*([a-z0-9\-]+(?:\s*(?:[\[({<]\s*dot\s*[\])}>]|\.)\s*[a-z0-9\-]+)+)`),
+		dotWord:    regexp.MustCompile(`(?i)\s*[\[({<]\s*dot\s*[\])}>]\s*`),
 	}
 }
 
 func (e *EmailsExtractor) Name() string { return "emails" }
 
 func (e *EmailsExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem, error) {
-	html := page.RenderedHTML
-	if html == "" {
-		html = page.RawHTML
+	raw := page.RenderedHTML
+	if raw == "" {
+		raw = page.RawHTML
 	}
-	if html == "" {
+	if raw == "" {
 		return nil, nil
 	}
 
@@ -49,42 +61,158 @@ func (e *EmailsExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem
 			strings.Contains(email, "webpack") {
 			return
 		}
+		local, domain, ok := validEmail(email)
+		if !ok {
+			return
+		}
 		seen[email] = true
 		items = append(items, plugin.ExtractedItem{
 			Type:      "email",
 			Value:     email,
 			SourceURL: page.URL,
 			Metadata: map[string]string{
-				"source": source,
+				"source":     source,
+				"domain":     domain,
+				"confidence": strconv.FormatFloat(emailConfidence(local, source), 'f', 2, 64),
 			},
 		})
 	}
 
-	// Extract from mailto: links
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	doc, err := document(page)
 	if err == nil {
-		doc.Find(`a[href^="mailto:"]`).Each(func(_ int, s *goquery.Selection) {
+		// Extract from mailto: links
+		doc.Find(`a[href^="mailto:" i]`).Each(func(_ int, s *goquery.Selection) {
 			href, _ := s.Attr("href")
-			email := strings.TrimPrefix(href, "mailto:")
+			email := href[len("mailto:"):]
 			// Remove query params (e.g., ?subject=...)
 			if idx := strings.Index(email, "?"); idx != -1 {
 				email = email[:idx]
 			}
-			addEmail(email, "mailto_link")
+			if unescaped, err := url.PathUnescape(email); err == nil {
+				email = unescaped
+			}
+			// mailto: may list several recipients
+			for _, addr := range strings.Split(email, ",") {
+				addEmail(addr, "mailto_link")
+			}
+		})
+
+		// Cloudflare email protection hides addresses in data-cfemail or
+		// /cdn-cgi/l/email-protection#<hex> links
+		doc.Find(`[data-cfemail], a[href*="/cdn-cgi/l/email-protection#"]`).Each(func(_ int, s *goquery.Selection) {
+			encoded, ok := s.Attr("data-cfemail")
+			if !ok {
+				href, _ := s.Attr("href")
+				encoded = href[strings.LastIndex(href, "#")+1:]
+			}
+			if email := decodeCFEmail(encoded); email != "" {
+				addEmail(email, "cloudflare")
+			}
 		})
 	}
 
-	// Extract from page text via regex
-	// Strip HTML tags first for cleaner extraction
-	textContent := stripTags(html)
-	matches := e.pattern.FindAllString(textContent, -1)
-	for _, match := range matches {
+	// Extract from page text via regex, with entities decoded so
+	// &#64; and &commat; read as @
+	textContent := html.UnescapeString(stripTags(raw))
+	for _, match := range e.pattern.FindAllString(textContent, -1) {
 		addEmail(match, "page_text")
 	}
+	for _, m := range e.obfuscated.FindAllStringSubmatch(textContent, -1) {
+		addEmail(m[1]+"@"+e.dotWord.ReplaceAllString(m[2], "."), "deobfuscated")
+	}
 
 	return items, nil
 }
 
+// decodeCFEmail reverses Cloudflare's email obfuscation: the first byte
+// of the hex string is an XOR key for the rest.
+func decodeCFEmail(encoded string) string {
+	b, err := hex.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(b) < 2 {
+		return ""
+	}
+	out := make([]byte, len(b)-1)
+	for i := range out {
+		out[i] = b[i+1] ^ b[0]
+	}
+	return string(out)
+}
+
+// reservedTLDs never resolve on the public internet (RFC 2606, RFC 6761).
+var reservedTLDs = map[string]bool{
+	"test": true, "example": true, "invalid": true, "localhost": true, "local": true,
+}
+
+// validEmail checks an address offline: the local part's length and dots,
+// and a domain that could carry an MX record — 2+ labels of at most 63
+// letters, digits or inner hyphens, 253 characters overall and a real TLD.
+func validEmail(email string) (local, domain string, ok bool) {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return "", "", false
+	}
+	local, domain = email[:at], strings.TrimSuffix(email[at+1:], ".")
+	if len(local) > 64 || strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") || strings.Contains(local, "..") {
+		return "", "", false
+	}
+	if len(domain) > 253 {
+		return "", "", false
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "", "", false
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return "", "", false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return "", "", false
+			}
+		}
+	}
+	tld := labels[len(labels)-1]
+	if reservedTLDs[tld] {
+		return "", "", false
+	}
+	if !strings.HasPrefix(tld, "xn--") {
+		if len(tld) < 2 {
+			return "", "", false
+		}
+		for _, r := range tld {
+			if r < 'a' || r > 'z' {
+				return "", "", false
+			}
+		}
+	}
+	return local, domain, true
+}
+
+// emailConfidence scores how likely an address is a real contact.
+func emailConfidence(local, source string) float64 {
+	score := map[string]float64{
+		"mailto_link":  0.9,
+		"cloudflare":   0.9,
+		"page_text":    0.7,
+		"deobfuscated": 0.6,
+	}[source]
+	// Long hex or digit runs are usually generated IDs, not mailboxes
+	hexish := 0
+	for _, r := range local {
+		if r >= '0' && r <= '9' || r >= 'a' && r <= 'f' {
+			hexish++
+		}
+	}
+	if len(local) >= 16 && hexish == len(local) {
+		score -= 0.4
+	}
+	if strings.HasPrefix(local, "noreply") || strings.HasPrefix(local, "no-reply") {
+		score -= 0.2
+	}
+	return max(score, 0.1)
+}
+
 // stripTags removes HTML tags from a string for text extraction.
 func stripTags(s string) string {
 	var builder strings.Builder
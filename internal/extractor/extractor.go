@@ -1,15 +1,45 @@
 package extractor
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/ramkansal/gofang/pkg/plugin"
 )
 
+// DefaultTimeout bounds how long a single extractor may spend on a page.
+const DefaultTimeout = 30 * time.Second
+
+// ItemTypes lists the item types the built-in extractors produce, in the
+// order summaries report them.
+var ItemTypes = []string{"link", "form", "email", "phone", "social", "metadata", "structured_data", "asset", "api_endpoint", "redirect", "redirect_issue", "security", "a11y", "secret"}
+
 // Registry holds all available extractors.
 type Registry struct {
 	extractors []plugin.Extractor
+
+	// Timeout bounds each extractor's run on a page; 0 means no limit.
+	Timeout time.Duration
+
+	mu        sync.Mutex
+	abandoned map[string]int // timed-out runs per extractor that haven't returned yet
 }
 
-// NewRegistry creates a registry with all built-in extractors.
+// run tracks one extractor's goroutine for a page.
+type run struct{ finished, abandoned bool }
+
+// errStillRunning is reported for an extractor skipped because its run on
+// an earlier page timed out and hasn't returned yet.
+var errStillRunning = errors.New("skipped: still running on an earlier page")
+
+// NewRegistry creates a registry with the built-in extractors that run by
+// default. The security and accessibility audits add several findings to
+// every page, so they are opt-in: register NewSecurityExtractor and
+// NewAccessibilityExtractor to enable them.
 func NewRegistry() *Registry {
 	return &Registry{
 		extractors: []plugin.Extractor{
@@ -19,9 +49,12 @@ func NewRegistry() *Registry {
 			NewPhonesExtractor(),
 			NewSocialExtractor(),
 			NewMetadataExtractor(),
+			NewStructuredDataExtractor(),
 			NewAssetsExtractor(),
 			NewAPIExtractor(),
+			NewRedirectsExtractor(),
 		},
+		Timeout: DefaultTimeout,
 	}
 }
 
@@ -30,18 +63,189 @@ func (r *Registry) Register(ext plugin.Extractor) {
 	r.extractors = append(r.extractors, ext)
 }
 
-// ExtractAll runs all registered extractors against the given page.
-func (r *Registry) ExtractAll(page *plugin.PageData) ([]plugin.ExtractedItem, error) {
-	var allItems []plugin.ExtractedItem
+// Replace swaps the registered extractor with the same name for ext, or
+// adds ext if there is none.
+func (r *Registry) Replace(ext plugin.Extractor) {
+	for i, existing := range r.extractors {
+		if existing.Name() == ext.Name() {
+			r.extractors[i] = ext
+			return
+		}
+	}
+	r.Register(ext)
+}
+
+// Select keeps only the extractors named in enable (all of them when
+// enable is empty), minus those named in disable. Unknown names are an
+// error so typos don't silently disable everything.
+func (r *Registry) Select(enable, disable []string) error {
+	known := make(map[string]bool, len(r.extractors))
+	for _, ext := range r.extractors {
+		known[ext.Name()] = true
+	}
+	for _, name := range append(append([]string(nil), enable...), disable...) {
+		if !known[name] {
+			available := r.Names()
+			sort.Strings(available)
+			return fmt.Errorf("unknown extractor %q (available: %s)", name, strings.Join(available, ", "))
+		}
+	}
+
+	keep := func(name string) bool {
+		for _, d := range disable {
+			if d == name {
+				return false
+			}
+		}
+		if len(enable) == 0 {
+			return true
+		}
+		for _, e := range enable {
+			if e == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	var selected []plugin.Extractor
+	for _, ext := range r.extractors {
+		if keep(ext.Name()) {
+			selected = append(selected, ext)
+		}
+	}
+	r.extractors = selected
+	return nil
+}
+
+// Has reports whether an extractor with the given name is registered.
+func (r *Registry) Has(name string) bool {
 	for _, ext := range r.extractors {
-		items, err := ext.Extract(page)
-		if err != nil {
-			// log but don't abort — other extractors should still run
+		if ext.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Error is a failure of one extractor on one page: an error it returned,
+// a panic it raised or a timeout.
+type Error struct {
+	Extractor string
+	URL       string
+	Err       error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("extractor %s on %s: %v", e.Extractor, e.URL, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// ExtractAll runs all registered extractors concurrently against the given
+// page. Items come back in registration order. An extractor that fails,
+// panics or exceeds the timeout contributes no items and an Error instead;
+// the others are unaffected.
+func (r *Registry) ExtractAll(page *plugin.PageData) ([]plugin.ExtractedItem, []*Error) {
+	type outcome struct {
+		items []plugin.ExtractedItem
+		err   error
+	}
+
+	// Parse the page once up front and share it with every extractor
+	shared := &parsedDoc{}
+	_, _ = shared.get(page)
+	documents.Store(page, shared)
+	defer documents.Delete(page)
+
+	// A run that outlives the timeout is left to finish in the background;
+	// the extractor is skipped on later pages until it does, so a stuck
+	// extractor holds at most one goroutine per page being extracted
+	runs := make([]*run, len(r.extractors))
+	results := make([]chan outcome, len(r.extractors))
+	for i, ext := range r.extractors {
+		// Buffered so an abandoned run can still deliver and exit
+		results[i] = make(chan outcome, 1)
+		r.mu.Lock()
+		stuck := r.abandoned[ext.Name()] > 0
+		r.mu.Unlock()
+		if stuck {
+			results[i] <- outcome{err: errStillRunning}
 			continue
 		}
-		allItems = append(allItems, items...)
+		runs[i] = &run{}
+		go func(ext plugin.Extractor, st *run, done chan<- outcome) {
+			defer func() {
+				r.mu.Lock()
+				st.finished = true
+				if st.abandoned {
+					r.abandoned[ext.Name()]--
+				}
+				r.mu.Unlock()
+			}()
+			defer func() {
+				if v := recover(); v != nil {
+					done <- outcome{err: fmt.Errorf("panic: %v", v)}
+				}
+			}()
+			items, err := ext.Extract(page)
+			done <- outcome{items: items, err: err}
+		}(ext, runs[i], results[i])
+	}
+
+	var deadline <-chan time.Time
+	if r.Timeout > 0 {
+		timer := time.NewTimer(r.Timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	var allItems []plugin.ExtractedItem
+	var errs []*Error
+	timedOut := false
+	for i, ext := range r.extractors {
+		var res outcome
+		late := false
+		if timedOut {
+			// Past the deadline, only collect what has already finished
+			select {
+			case res = <-results[i]:
+			default:
+				late = true
+			}
+		} else {
+			select {
+			case res = <-results[i]:
+			case <-deadline:
+				timedOut, late = true, true
+			}
+		}
+		if late {
+			res.err = fmt.Errorf("timed out after %s", r.Timeout)
+			r.abandon(ext.Name(), runs[i])
+		}
+		if res.err != nil {
+			errs = append(errs, &Error{Extractor: ext.Name(), URL: page.URL, Err: res.err})
+			continue
+		}
+		allItems = append(allItems, res.items...)
+	}
+	return allItems, errs
+}
+
+// abandon marks a timed-out run so the extractor is skipped until it
+// returns.
+func (r *Registry) abandon(name string, st *run) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if st.finished {
+		return
+	}
+	st.abandoned = true
+	if r.abandoned == nil {
+		r.abandoned = make(map[string]int)
 	}
-	return allItems, nil
+	r.abandoned[name]++
 }
 
 // Names returns the names of all registered extractors.
@@ -24,7 +24,7 @@ func (e *LinksExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem,
 		return nil, nil
 	}
 
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	doc, err := document(page)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +87,54 @@ func (e *LinksExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem,
 		})
 	})
 
+	// Client-side routes recorded by the browser fetcher
+	for _, route := range page.SPARoutes {
+		if seen[route] {
+			continue
+		}
+		seen[route] = true
+
+		linkType := "external"
+		if u, err := url.Parse(route); err == nil && u.Host == baseURL.Host {
+			linkType = "internal"
+		}
+		items = append(items, plugin.ExtractedItem{
+			Type:      "link",
+			Value:     route,
+			SourceURL: page.URL,
+			Metadata: map[string]string{
+				"link_type": linkType,
+				"source":    "spa_route",
+			},
+		})
+	}
+
+	// Links uncovered by the browser's interaction phase that are no longer
+	// present in the final DOM (e.g. swapped-out tabs or click navigations)
+	for _, step := range page.Interactions {
+		for _, link := range step.NewLinks {
+			if seen[link] {
+				continue
+			}
+			seen[link] = true
+
+			linkType := "external"
+			if u, err := url.Parse(link); err == nil && u.Host == baseURL.Host {
+				linkType = "internal"
+			}
+			items = append(items, plugin.ExtractedItem{
+				Type:      "link",
+				Value:     link,
+				SourceURL: page.URL,
+				Metadata: map[string]string{
+					"link_type": linkType,
+					"source":    "interaction",
+					"action":    step.Action,
+				},
+			})
+		}
+	}
+
 	return items, nil
 }
 
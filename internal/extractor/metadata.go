@@ -1,6 +1,7 @@
 package extractor
 
 import (
+	"net/url"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -23,7 +24,7 @@ func (e *MetadataExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedIt
 		return nil, nil
 	}
 
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	doc, err := document(page)
 	if err != nil {
 		return nil, err
 	}
@@ -101,6 +102,26 @@ func (e *MetadataExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedIt
 		}
 	})
 
+	// Alternate language versions, resolved against the page
+	baseURL, _ := url.Parse(page.FinalURL)
+	if baseURL == nil {
+		baseURL, _ = url.Parse(page.URL)
+	}
+	doc.Find(`link[rel="alternate"][hreflang]`).Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		hreflang, _ := s.Attr("hreflang")
+		target := resolveURL(baseURL, strings.TrimSpace(href))
+		if target == "" || strings.TrimSpace(hreflang) == "" {
+			return
+		}
+		items = append(items, plugin.ExtractedItem{
+			Type:      "metadata",
+			Value:     target,
+			SourceURL: page.URL,
+			Metadata:  map[string]string{"field": "hreflang", "hreflang": strings.TrimSpace(hreflang)},
+		})
+	})
+
 	// Language
 	lang, exists := doc.Find("html").Attr("lang")
 	if exists && lang != "" {
@@ -112,18 +133,5 @@ func (e *MetadataExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedIt
 		})
 	}
 
-	// JSON-LD structured data
-	doc.Find(`script[type="application/ld+json"]`).Each(func(i int, s *goquery.Selection) {
-		jsonLD := strings.TrimSpace(s.Text())
-		if jsonLD != "" {
-			items = append(items, plugin.ExtractedItem{
-				Type:      "metadata",
-				Value:     truncate(jsonLD, 2000),
-				SourceURL: page.URL,
-				Metadata:  map[string]string{"field": "json-ld", "index": string(rune('0' + i))},
-			})
-		}
-	})
-
 	return items, nil
 }
@@ -1,26 +1,29 @@
 package extractor
 
 import (
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/ramkansal/gofang/pkg/plugin"
 )
 
-// PhonesExtractor extracts phone numbers from page content.
+// PhonesExtractor extracts phone numbers from tel: links and page text and
+// normalizes them to E.164. National numbers are resolved with a region
+// hint taken from the page language or the host's country-code TLD.
 type PhonesExtractor struct {
-	patterns []*regexp.Regexp
+	candidate *regexp.Regexp
+	date      *regexp.Regexp
 }
 
 func NewPhonesExtractor() *PhonesExtractor {
 	return &PhonesExtractor{
-		patterns: []*regexp.Regexp{
-			// International format: +1-234-567-8900, +44 20 7946 0958
-			regexp.MustCompile(`\+?1?[\s.-]?\(?\d{3}\)?[\s.-]?\d{3}[\s.-]?\d{4}`),
-			// Intl with country code: +XX XXXXXXXXXX
-			regexp.MustCompile(`\+\d{1,3}[\s.-]?\d{4,14}`),
-		},
+		// Digits with the separators people write numbers with:
+		// +44 20 7946 0958, (212) 555-0100, 0049-30-1234567
+		candidate: regexp.MustCompile(`\+?\(?\d[\d \t.\-()/]{5,22}\d`),
+		date:      regexp.MustCompile(`^(\d{4}[./-]\d{1,2}[./-]\d{1,2}|\d{1,2}[./-]\d{1,2}[./-]\d{2,4}|\d{4}\s*-\s*\d{4})$`),
 	}
 }
 
@@ -35,6 +38,9 @@ func (e *PhonesExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem
 		return nil, nil
 	}
 
+	doc, _ := document(page)
+	region, hint := phoneRegion(page, doc)
+
 	seen := make(map[string]bool)
 	var items []plugin.ExtractedItem
 
@@ -42,43 +48,107 @@ func (e *PhonesExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem
 		phone = strings.TrimSpace(phone)
 		// Normalize for dedup: remove all non-digit chars except +
 		normalized := normalizePhone(phone)
-		if len(normalized) < 7 || seen[normalized] {
+		digits := strings.TrimPrefix(normalized, "+")
+		if len(digits) < 7 || len(digits) > 15 {
 			return
 		}
-		seen[normalized] = true
+
+		num := parsePhone(normalized, region)
+		if source == "page_text" {
+			if e.date.MatchString(phone) {
+				return
+			}
+			// Bare digit runs are timestamps, order numbers and IDs far
+			// more often than phone numbers
+			if !strings.HasPrefix(phone, "+") && !isGrouped(phone) {
+				return
+			}
+			// A number that doesn't parse must at least be as long as one
+			if num.invalid || num.e164 == "" && len(digits) < 10 {
+				return
+			}
+		}
+
+		key := normalized
+		if num.e164 != "" {
+			key = num.e164
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		meta := map[string]string{
+			"normalized": normalized,
+			"source":     source,
+			"confidence": strconv.FormatFloat(phoneConfidence(phone, source, num), 'f', 2, 64),
+		}
+		if num.e164 != "" {
+			meta["e164"] = num.e164
+		}
+		if num.country != "" {
+			meta["country"] = num.country
+		}
+		if !num.international && region != "" {
+			meta["region_hint"] = hint
+		}
 		items = append(items, plugin.ExtractedItem{
 			Type:      "phone",
 			Value:     phone,
 			SourceURL: page.URL,
-			Metadata: map[string]string{
-				"normalized": normalized,
-				"source":     source,
-			},
+			Metadata:  meta,
 		})
 	}
 
 	// Extract from tel: links
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
-	if err == nil {
+	if doc != nil {
 		doc.Find(`a[href^="tel:"]`).Each(func(_ int, s *goquery.Selection) {
 			href, _ := s.Attr("href")
 			phone := strings.TrimPrefix(href, "tel:")
+			if unescaped, err := url.PathUnescape(phone); err == nil {
+				phone = unescaped
+			}
+			// Drop extensions and parameters (;ext=12, ;phone-context=...)
+			if idx := strings.Index(phone, ";"); idx != -1 {
+				phone = phone[:idx]
+			}
 			addPhone(phone, "tel_link")
 		})
 	}
 
-	// Extract from page text via regex
+	// Extract from the visible page text via regex; script and style
+	// hold timestamps and IDs, not numbers meant for people
 	textContent := stripTags(html)
-	for _, pattern := range e.patterns {
-		matches := pattern.FindAllString(textContent, -1)
-		for _, match := range matches {
-			addPhone(match, "page_text")
+	if doc != nil {
+		textContent = visibleText(doc)
+	}
+	for _, loc := range e.candidate.FindAllStringIndex(textContent, -1) {
+		// Skip digit runs inside words, IDs and email addresses
+		if loc[0] > 0 && isWordByte(textContent[loc[0]-1]) ||
+			loc[1] < len(textContent) && isWordByte(textContent[loc[1]]) {
+			continue
+		}
+		match := textContent[loc[0]:loc[1]]
+		cutset := " \t.-/"
+		if !strings.Contains(match, ")") {
+			cutset += "("
 		}
+		addPhone(strings.Trim(match, cutset), "page_text")
 	}
 
 	return items, nil
 }
 
+// isGrouped reports whether a number is written with separators, as in
+// 212-555-0100, rather than as one run of digits.
+func isGrouped(phone string) bool {
+	return strings.ContainsAny(strings.TrimPrefix(phone, "+"), " \t.-()/")
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '@' || b == '_'
+}
+
 func normalizePhone(s string) string {
 	var b strings.Builder
 	for _, r := range s {
@@ -88,3 +158,196 @@ func normalizePhone(s string) string {
 	}
 	return b.String()
 }
+
+// ---------- E.164 ----------
+
+// phonePlan describes a region's numbering: its country calling code, the
+// trunk prefix dialled before national numbers and the length range of
+// the national significant number.
+type phonePlan struct {
+	code     string
+	trunk    string
+	min, max int
+}
+
+var phonePlans = map[string]phonePlan{
+	"US": {"1", "1", 10, 10}, "CA": {"1", "1", 10, 10},
+	"GB": {"44", "0", 9, 10}, "IE": {"353", "0", 7, 9},
+	"DE": {"49", "0", 6, 13}, "AT": {"43", "0", 4, 13}, "CH": {"41", "0", 9, 9},
+	"FR": {"33", "0", 9, 9}, "BE": {"32", "0", 8, 9}, "NL": {"31", "0", 9, 9},
+	"LU": {"352", "", 4, 11}, "ES": {"34", "", 9, 9}, "PT": {"351", "", 9, 9},
+	"IT": {"39", "", 6, 11}, "GR": {"30", "", 10, 10}, "PL": {"48", "", 9, 9},
+	"CZ": {"420", "", 9, 9}, "HU": {"36", "06", 8, 9}, "RO": {"40", "0", 9, 9},
+	"SE": {"46", "0", 7, 9}, "NO": {"47", "", 8, 8}, "DK": {"45", "", 8, 8},
+	"FI": {"358", "0", 5, 12}, "UA": {"380", "0", 9, 9}, "RU": {"7", "8", 10, 10},
+	"TR": {"90", "0", 10, 10}, "IL": {"972", "0", 8, 9}, "AE": {"971", "0", 8, 9},
+	"SA": {"966", "0", 9, 9}, "EG": {"20", "0", 9, 10}, "ZA": {"27", "0", 9, 9},
+	"NG": {"234", "0", 8, 10}, "KE": {"254", "0", 9, 9}, "IN": {"91", "0", 10, 10},
+	"PK": {"92", "0", 9, 10}, "CN": {"86", "0", 7, 11}, "HK": {"852", "", 8, 8},
+	"JP": {"81", "0", 9, 10}, "KR": {"82", "0", 8, 10}, "SG": {"65", "", 8, 8},
+	"ID": {"62", "0", 9, 12}, "PH": {"63", "0", 10, 10}, "TH": {"66", "0", 8, 9},
+	"VN": {"84", "0", 9, 10}, "AU": {"61", "0", 9, 9}, "NZ": {"64", "0", 8, 10},
+	"BR": {"55", "0", 10, 11}, "MX": {"52", "", 10, 10}, "AR": {"54", "0", 10, 10},
+	"CL": {"56", "", 9, 9}, "CO": {"57", "", 10, 10},
+}
+
+// langRegions maps languages spoken mainly in one region to that region;
+// languages such as English or Spanish are too ambiguous to hint at one.
+var langRegions = map[string]string{
+	"de": "DE", "fr": "FR", "it": "IT", "nl": "NL", "pl": "PL", "cs": "CZ",
+	"hu": "HU", "ro": "RO", "sv": "SE", "nb": "NO", "nn": "NO", "no": "NO",
+	"da": "DK", "fi": "FI", "uk": "UA", "ru": "RU", "tr": "TR", "he": "IL",
+	"el": "GR", "ja": "JP", "ko": "KR", "zh": "CN", "th": "TH", "vi": "VN",
+	"id": "ID", "hi": "IN",
+}
+
+// phoneRegion picks the region used for national numbers: a region
+// subtag in the page language (en-GB), else the country-code TLD, else a
+// language tied to one region. hint says which one was used.
+func phoneRegion(page *plugin.PageData, doc *goquery.Document) (region, hint string) {
+	lang := ""
+	if doc != nil {
+		lang, _ = doc.Find("html").First().Attr("lang")
+	}
+	if lang == "" && page.Headers != nil {
+		lang = page.Headers.Get("Content-Language")
+	}
+	lang = strings.TrimSpace(strings.Split(lang, ",")[0])
+	parts := strings.FieldsFunc(lang, func(r rune) bool { return r == '-' || r == '_' })
+
+	for _, p := range parts[min(1, len(parts)):] {
+		if r := strings.ToUpper(p); len(p) == 2 && phonePlans[r].code != "" {
+			return r, "lang"
+		}
+	}
+
+	target := page.FinalURL
+	if target == "" {
+		target = page.URL
+	}
+	if u, err := url.Parse(target); err == nil {
+		host := u.Hostname()
+		tld := strings.ToUpper(host[strings.LastIndex(host, ".")+1:])
+		if tld == "UK" {
+			tld = "GB"
+		}
+		if phonePlans[tld].code != "" {
+			return tld, "tld"
+		}
+	}
+
+	if len(parts) > 0 {
+		if r, ok := langRegions[strings.ToLower(parts[0])]; ok {
+			return r, "lang"
+		}
+	}
+	return "", ""
+}
+
+type phoneNumber struct {
+	e164          string
+	country       string // region, when the calling code identifies one
+	international bool   // written with a + or international dialling prefix
+	invalid       bool   // breaks the numbering rules of its plan
+}
+
+// parsePhone converts a normalized number (digits, optional leading +) to
+// E.164. Numbers without an international prefix need a region; numbers
+// that do not fit the region's lengths are left unnormalized.
+func parsePhone(normalized, region string) phoneNumber {
+	plan, hasPlan := phonePlans[region]
+	digits := strings.TrimPrefix(normalized, "+")
+
+	international := strings.HasPrefix(normalized, "+")
+	switch {
+	case international:
+	case strings.HasPrefix(digits, "00") && plan.code != "1":
+		digits, international = digits[2:], true
+	case strings.HasPrefix(digits, "011") && plan.code == "1":
+		digits, international = digits[3:], true
+	}
+
+	if international {
+		if strings.HasPrefix(digits, "1") && !validNANP(digits[1:]) {
+			return phoneNumber{international: true, invalid: true}
+		}
+		for n := 1; n <= 3 && n < len(digits); n++ {
+			code, nsn := digits[:n], digits[n:]
+			country, ok := regionForCode(code, nsn, region)
+			if !ok {
+				continue
+			}
+			return phoneNumber{e164: "+" + digits, country: country, international: true}
+		}
+		// Unknown calling code: keep it if it fits E.164 at all
+		if len(digits) >= 8 && len(digits) <= 15 {
+			return phoneNumber{e164: "+" + digits, international: true}
+		}
+		return phoneNumber{international: true}
+	}
+
+	if !hasPlan {
+		return phoneNumber{}
+	}
+	nsn := digits
+	switch {
+	case plan.trunk != "" && strings.HasPrefix(nsn, plan.trunk) && len(nsn)-len(plan.trunk) >= plan.min:
+		nsn = nsn[len(plan.trunk):]
+	case plan.trunk != "" && plan.code != "1":
+		// Outside NANP, national numbers are written with the trunk prefix
+		return phoneNumber{}
+	}
+	if len(nsn) < plan.min || len(nsn) > plan.max {
+		return phoneNumber{}
+	}
+	if plan.code == "1" && !validNANP(nsn) {
+		return phoneNumber{invalid: true}
+	}
+	return phoneNumber{e164: "+" + plan.code + nsn, country: region}
+}
+
+// validNANP reports whether nsn is a North American number: a ten-digit
+// number whose area code and exchange code start with 2-9.
+func validNANP(nsn string) bool {
+	return len(nsn) == 10 && nsn[0] >= '2' && nsn[3] >= '2'
+}
+
+// regionForCode reports whether code is a known calling code whose plan
+// admits nsn, preferring the hinted region when several share the code.
+func regionForCode(code, nsn, hint string) (string, bool) {
+	if p, ok := phonePlans[hint]; ok && p.code == code && len(nsn) >= p.min && len(nsn) <= p.max {
+		return hint, true
+	}
+	var matches []string
+	for region, p := range phonePlans {
+		if p.code == code && len(nsn) >= p.min && len(nsn) <= p.max {
+			matches = append(matches, region)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", false
+	case 1:
+		return matches[0], true
+	}
+	return "", true // +1 and +7 span several regions
+}
+
+// phoneConfidence scores how likely a match is a real phone number.
+func phoneConfidence(raw, source string, num phoneNumber) float64 {
+	score := 0.3
+	if source == "tel_link" {
+		score = 0.6
+	}
+	switch {
+	case num.e164 != "" && num.international:
+		score += 0.3
+	case num.e164 != "":
+		score += 0.2
+	}
+	// Grouped the way people write numbers, not a bare digit run
+	if source == "page_text" && strings.ContainsAny(raw, " -()") {
+		score += 0.1
+	}
+	return min(score, 1)
+}
@@ -0,0 +1,129 @@
+package extractor
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// longRedirectChain is the number of hops above which a chain is flagged.
+const longRedirectChain = 3
+
+// RedirectsExtractor reports a page's redirect chain and flags loops, long
+// chains, HTTPS to HTTP downgrades and open-redirect candidates.
+type RedirectsExtractor struct{}
+
+func NewRedirectsExtractor() *RedirectsExtractor { return &RedirectsExtractor{} }
+
+func (e *RedirectsExtractor) Name() string { return "redirects" }
+
+func (e *RedirectsExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem, error) {
+	hops := page.Redirects
+	if len(hops) == 0 {
+		return nil, nil
+	}
+
+	var items []plugin.ExtractedItem
+	issue := func(kind, severity, value, detail string) {
+		items = append(items, plugin.ExtractedItem{
+			Type:      "redirect_issue",
+			Value:     value,
+			SourceURL: page.URL,
+			Metadata: map[string]string{
+				"issue":    kind,
+				"severity": severity,
+				"detail":   detail,
+			},
+		})
+	}
+
+	// The chain itself
+	steps := make([]string, 0, len(hops))
+	for _, h := range hops {
+		steps = append(steps, fmt.Sprintf("%s (%d)", h.URL, h.StatusCode))
+	}
+	items = append(items, plugin.ExtractedItem{
+		Type:      "redirect",
+		Value:     page.URL,
+		SourceURL: page.URL,
+		Metadata: map[string]string{
+			"hops":      fmt.Sprintf("%d", len(hops)),
+			"chain":     strings.Join(steps, " -> "),
+			"final_url": page.FinalURL,
+		},
+	})
+
+	// Loops: a hop URL repeats, or a cut-off chain points back into itself
+	visited := make(map[string]bool)
+	for _, h := range hops {
+		visited[h.URL] = true
+		target := resolveLocation(h.URL, h.Location)
+		if visited[target] {
+			issue("loop", "high", h.URL, "redirect chain revisits "+target)
+			break
+		}
+	}
+
+	if len(hops) > longRedirectChain {
+		issue("long_chain", "low", page.URL,
+			fmt.Sprintf("%d redirects before reaching %s", len(hops), page.FinalURL))
+	}
+
+	for _, h := range hops {
+		from, err := url.Parse(h.URL)
+		if err != nil {
+			continue
+		}
+		to, err := url.Parse(resolveLocation(h.URL, h.Location))
+		if err != nil {
+			continue
+		}
+
+		if from.Scheme == "https" && to.Scheme == "http" {
+			issue("https_downgrade", "high", h.URL, "redirects from HTTPS to "+to.String())
+		}
+
+		if param, ok := controllingParam(from, h.Location, to); ok {
+			severity := "low"
+			if !strings.EqualFold(to.Hostname(), from.Hostname()) {
+				severity = "medium"
+			}
+			issue("open_redirect", severity, h.URL,
+				fmt.Sprintf("query parameter %q controls Location: %s", param, h.Location))
+		}
+	}
+
+	return items, nil
+}
+
+// controllingParam reports which query parameter of from, if any, appears
+// to decide the redirect target.
+func controllingParam(from *url.URL, location string, to *url.URL) (string, bool) {
+	for name, values := range from.Query() {
+		for _, v := range values {
+			if len(v) < 4 {
+				continue
+			}
+			if v == location || strings.HasPrefix(location, v) {
+				return name, true
+			}
+			// A URL-valued parameter whose host became the target
+			if pu, err := url.Parse(v); err == nil && pu.Host != "" &&
+				strings.EqualFold(pu.Hostname(), to.Hostname()) &&
+				!strings.EqualFold(to.Hostname(), from.Hostname()) {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+func resolveLocation(base, location string) string {
+	b, err := url.Parse(base)
+	if err != nil {
+		return location
+	}
+	return resolveURL(b, location)
+}
@@ -0,0 +1,310 @@
+package extractor
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/ramkansal/gofang/pkg/plugin"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule is one declarative extraction rule. Exactly one of Regex, CSS or
+// XPath must be set; the matches become items of the given Type.
+type Rule struct {
+	Name  string `yaml:"name"` // defaults to Type
+	Type  string `yaml:"type"`
+	Regex string `yaml:"regex"`
+	Group int    `yaml:"group"` // regex capture group (0 = whole match)
+	CSS   string `yaml:"css"`
+	XPath string `yaml:"xpath"`
+	Attr  string `yaml:"attr"` // CSS/XPath: read this attribute instead of the text
+
+	// URLs and ExcludeURLs are regexes on the page URL; with URLs set the
+	// rule only runs on pages matching at least one of them.
+	URLs        []string `yaml:"urls"`
+	ExcludeURLs []string `yaml:"exclude_urls"`
+
+	// Post lists post-processing steps applied in order: trim,
+	// collapse (whitespace), lowercase, uppercase, absolute (resolve
+	// against the page URL) and dedupe.
+	Post []string `yaml:"post"`
+
+	re      *regexp.Regexp
+	css     cascadia.Selector
+	xpath   *xpath.Expr
+	urls    []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+var postSteps = map[string]bool{
+	"trim": true, "collapse": true, "lowercase": true, "uppercase": true, "absolute": true, "dedupe": true,
+}
+
+// RulesExtractor runs user-defined rules loaded from a YAML file, so new
+// item types can be scraped without writing an extractor.
+type RulesExtractor struct {
+	rules []Rule
+}
+
+// NewRulesExtractor loads and validates the rules in path.
+func NewRulesExtractor(path string) (*RulesExtractor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read extraction rules: %w", err)
+	}
+	var file struct {
+		Rules []Rule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse extraction rules %s: %w", path, err)
+	}
+	if len(file.Rules) == 0 {
+		return nil, fmt.Errorf("%s: no rules defined", path)
+	}
+
+	for i := range file.Rules {
+		if err := file.Rules[i].compile(); err != nil {
+			r := file.Rules[i]
+			name := r.Name
+			if name == "" {
+				name = "#" + strconv.Itoa(i+1)
+			}
+			return nil, fmt.Errorf("%s: rule %s: %w", path, name, err)
+		}
+	}
+	return &RulesExtractor{rules: file.Rules}, nil
+}
+
+func (r *Rule) compile() error {
+	if r.Type == "" {
+		return fmt.Errorf("type is required")
+	}
+	if r.Name == "" {
+		r.Name = r.Type
+	}
+
+	kinds := 0
+	for _, s := range []string{r.Regex, r.CSS, r.XPath} {
+		if s != "" {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("exactly one of regex, css or xpath is required")
+	}
+
+	var err error
+	switch {
+	case r.Regex != "":
+		if r.re, err = regexp.Compile(r.Regex); err != nil {
+			return err
+		}
+		if r.Group > r.re.NumSubexp() {
+			return fmt.Errorf("group %d out of range", r.Group)
+		}
+		if r.Attr != "" {
+			return fmt.Errorf("attr only applies to css and xpath rules")
+		}
+	case r.CSS != "":
+		if r.css, err = cascadia.Compile(r.CSS); err != nil {
+			return fmt.Errorf("css: %w", err)
+		}
+	case r.XPath != "":
+		if r.xpath, err = xpath.Compile(r.XPath); err != nil {
+			return fmt.Errorf("xpath: %w", err)
+		}
+	}
+
+	for _, p := range r.URLs {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("urls: %w", err)
+		}
+		r.urls = append(r.urls, re)
+	}
+	for _, p := range r.ExcludeURLs {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("exclude_urls: %w", err)
+		}
+		r.exclude = append(r.exclude, re)
+	}
+	for i, step := range r.Post {
+		r.Post[i] = strings.ToLower(strings.TrimSpace(step))
+		if !postSteps[r.Post[i]] {
+			return fmt.Errorf("unknown post-processing step %q", step)
+		}
+	}
+	return nil
+}
+
+// applies reports whether the rule's URL filters admit pageURL.
+func (r *Rule) applies(pageURL string) bool {
+	for _, re := range r.exclude {
+		if re.MatchString(pageURL) {
+			return false
+		}
+	}
+	if len(r.urls) == 0 {
+		return true
+	}
+	for _, re := range r.urls {
+		if re.MatchString(pageURL) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *RulesExtractor) Name() string { return "rules" }
+
+func (e *RulesExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem, error) {
+	html := page.RenderedHTML
+	if html == "" {
+		html = page.RawHTML
+	}
+	if html == "" {
+		return nil, nil
+	}
+
+	baseURL, _ := url.Parse(page.FinalURL)
+	if baseURL == nil {
+		baseURL, _ = url.Parse(page.URL)
+	}
+
+	// The page's shared document serves both CSS and XPath rules
+	var (
+		doc    *goquery.Document
+		root   *htmlquery.NodeNavigator
+		docErr error
+	)
+
+	var items []plugin.ExtractedItem
+	for i := range e.rules {
+		rule := &e.rules[i]
+		if !rule.applies(page.URL) {
+			continue
+		}
+
+		var values []string
+		switch {
+		case rule.re != nil:
+			for _, m := range rule.re.FindAllStringSubmatch(html, -1) {
+				values = append(values, m[rule.Group])
+			}
+
+		case rule.css != nil:
+			if doc == nil && docErr == nil {
+				doc, docErr = document(page)
+			}
+			if docErr != nil {
+				return items, docErr
+			}
+			doc.FindMatcher(rule.css).Each(func(_ int, s *goquery.Selection) {
+				if rule.Attr == "" {
+					values = append(values, s.Text())
+				} else if v, ok := s.Attr(rule.Attr); ok {
+					values = append(values, v)
+				}
+			})
+
+		case rule.xpath != nil:
+			if doc == nil && docErr == nil {
+				doc, docErr = document(page)
+			}
+			if docErr != nil {
+				return items, docErr
+			}
+			if root == nil {
+				root = htmlquery.CreateXPathNavigator(doc.Nodes[0])
+			}
+			values = evalXPath(rule, root)
+		}
+
+		for _, v := range postProcess(rule.Post, values, baseURL) {
+			items = append(items, plugin.ExtractedItem{
+				Type:      rule.Type,
+				Value:     v,
+				SourceURL: page.URL,
+				Metadata:  map[string]string{"rule": rule.Name},
+			})
+		}
+	}
+	return items, nil
+}
+
+// evalXPath returns the string value of every node the rule selects, or
+// the single result of an expression such as count() or string().
+func evalXPath(rule *Rule, root *htmlquery.NodeNavigator) []string {
+	nav := root.Copy()
+	nav.MoveToRoot()
+
+	switch res := rule.xpath.Evaluate(nav).(type) {
+	case *xpath.NodeIterator:
+		var values []string
+		for res.MoveNext() {
+			cur := res.Current().(*htmlquery.NodeNavigator)
+			if rule.Attr == "" {
+				values = append(values, cur.Value())
+			} else if htmlquery.ExistsAttr(cur.Current(), rule.Attr) {
+				values = append(values, htmlquery.SelectAttr(cur.Current(), rule.Attr))
+			}
+		}
+		return values
+	case string:
+		return []string{res}
+	case float64:
+		return []string{strconv.FormatFloat(res, 'f', -1, 64)}
+	case bool:
+		return []string{strconv.FormatBool(res)}
+	}
+	return nil
+}
+
+// postProcess applies the rule's steps and drops empty values.
+func postProcess(steps []string, values []string, base *url.URL) []string {
+	var out []string
+	seen := make(map[string]bool)
+	dedupe := false
+	for _, s := range steps {
+		if s == "dedupe" {
+			dedupe = true
+		}
+	}
+
+	for _, v := range values {
+		for _, step := range steps {
+			switch step {
+			case "trim":
+				v = strings.TrimSpace(v)
+			case "collapse":
+				v = strings.Join(strings.Fields(v), " ")
+			case "lowercase":
+				v = strings.ToLower(v)
+			case "uppercase":
+				v = strings.ToUpper(v)
+			case "absolute":
+				v = resolveURL(base, strings.TrimSpace(v))
+			}
+		}
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		if dedupe {
+			if seen[v] {
+				continue
+			}
+			seen[v] = true
+		}
+		out = append(out, v)
+	}
+	return out
+}
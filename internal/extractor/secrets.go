@@ -0,0 +1,401 @@
+package extractor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ramkansal/gofang/pkg/plugin"
+	"gopkg.in/yaml.v3"
+)
+
+// Redaction modes for secrets in output.
+const (
+	RedactNone    = "none"    // print secrets as found
+	RedactPartial = "partial" // keep the first and last four characters
+	RedactFull    = "full"    // replace the whole secret
+)
+
+// SecretRule describes one kind of secret. Pattern is matched against page
+// and script text; Group selects the capture group holding the secret
+// itself (0 for the whole match).
+type SecretRule struct {
+	ID          string   `yaml:"id"`
+	Description string   `yaml:"description"`
+	Pattern     string   `yaml:"pattern"`
+	Group       int      `yaml:"group"`
+	Severity    string   `yaml:"severity"`
+	MinEntropy  float64  `yaml:"min_entropy"` // bits per character; 0 disables the check
+	Keywords    []string `yaml:"keywords"`    // skip the regex unless one of these occurs
+
+	re *regexp.Regexp
+}
+
+// defaultSecretRules is the built-in rule set.
+var defaultSecretRules = []SecretRule{
+	{ID: "aws_access_key_id", Description: "AWS access key ID", Severity: "high",
+		Pattern: `\b((?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16})\b`, Group: 1,
+		Keywords: []string{"AKIA", "ASIA", "ABIA", "ACCA"}},
+	{ID: "aws_secret_access_key", Description: "AWS secret access key", Severity: "high",
+		Pattern: `(?i)aws.{0,20}?(?:secret|private).{0,20}?['"\x60]([0-9a-zA-Z/+]{40})['"\x60]`, Group: 1,
+		MinEntropy: 4.0, Keywords: []string{"aws", "AWS"}},
+	{ID: "google_api_key", Description: "Google API key", Severity: "medium",
+		Pattern: `\b(AIza[0-9A-Za-z_\-]{35})`, Group: 1, Keywords: []string{"AIza"}},
+	{ID: "slack_token", Description: "Slack token", Severity: "high",
+		Pattern: `\b(xox[abposr]-[0-9A-Za-z\-]{10,})`, Group: 1, Keywords: []string{"xox"}},
+	{ID: "slack_webhook", Description: "Slack incoming webhook", Severity: "high",
+		Pattern: `(https://hooks\.slack\.com/services/T[0-9A-Z]+/B[0-9A-Z]+/[0-9A-Za-z]+)`, Group: 1,
+		Keywords: []string{"hooks.slack.com"}},
+	{ID: "github_token", Description: "GitHub token", Severity: "high",
+		Pattern: `\b((?:ghp|gho|ghu|ghs|ghr)_[0-9A-Za-z]{36}|github_pat_[0-9A-Za-z_]{82})\b`, Group: 1,
+		Keywords: []string{"ghp_", "gho_", "ghu_", "ghs_", "ghr_", "github_pat_"}},
+	{ID: "jwt", Description: "JSON Web Token", Severity: "medium",
+		Pattern: `\b(eyJ[0-9A-Za-z_\-]{8,}\.eyJ[0-9A-Za-z_\-]{8,}\.[0-9A-Za-z_\-]{10,})`, Group: 1,
+		Keywords: []string{"eyJ"}},
+	{ID: "private_key", Description: "Private key block", Severity: "high",
+		Pattern: `(-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----)`, Group: 1,
+		Keywords: []string{"PRIVATE KEY"}},
+	{ID: "generic_secret", Description: "High-entropy value assigned to a secret-like name", Severity: "low",
+		Pattern:    `(?i)\b[a-z0-9_\-]*(?:api[_\-]?key|secret|token|passw(?:or)?d|access[_\-]?key|auth[_\-]?key|client[_\-]?secret)[a-z0-9_\-]*['"]?\s*[:=]\s*['"\x60]([0-9A-Za-z_\-+/=.]{16,})['"\x60]`,
+		Group:      1,
+		MinEntropy: 3.5},
+}
+
+// SecretsConfig controls the secrets extractor.
+type SecretsConfig struct {
+	// RulesFile is an optional YAML file of extra rules, added to (or, with
+	// a matching id, replacing) the built-in ones.
+	RulesFile string
+
+	// Redact is one of RedactNone, RedactPartial or RedactFull.
+	Redact string
+
+	// FetchScript, when set, downloads external scripts so they are
+	// scanned too; InScope limits which script URLs are fetched.
+	FetchScript func(url string) (string, error)
+	InScope     func(url string) bool
+}
+
+// SecretsExtractor looks for leaked credentials in the page HTML, its
+// inline scripts and (optionally) the external scripts it loads. Each
+// script URL is fetched and scanned once per crawl.
+type SecretsExtractor struct {
+	cfg   SecretsConfig
+	rules []SecretRule
+
+	mu      sync.Mutex
+	scanned map[string]bool // script URLs already scanned
+}
+
+// NewSecretsExtractor compiles the built-in rules plus any from
+// cfg.RulesFile.
+func NewSecretsExtractor(cfg SecretsConfig) (*SecretsExtractor, error) {
+	switch cfg.Redact {
+	case "":
+		cfg.Redact = RedactPartial
+	case RedactNone, RedactPartial, RedactFull:
+	default:
+		return nil, fmt.Errorf("unknown redaction mode %q (want none, partial or full)", cfg.Redact)
+	}
+
+	rules := append([]SecretRule(nil), defaultSecretRules...)
+	if cfg.RulesFile != "" {
+		extra, err := loadSecretRules(cfg.RulesFile)
+		if err != nil {
+			return nil, err
+		}
+		rules = mergeSecretRules(rules, extra)
+	}
+	for i := range rules {
+		re, err := regexp.Compile(rules[i].Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("secret rule %q: %w", rules[i].ID, err)
+		}
+		if rules[i].Group > re.NumSubexp() {
+			return nil, fmt.Errorf("secret rule %q: group %d out of range", rules[i].ID, rules[i].Group)
+		}
+		if rules[i].Severity == "" {
+			rules[i].Severity = "medium"
+		}
+		rules[i].re = re
+	}
+
+	return &SecretsExtractor{cfg: cfg, rules: rules, scanned: make(map[string]bool)}, nil
+}
+
+func loadSecretRules(path string) ([]SecretRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read secret rules: %w", err)
+	}
+	var file struct {
+		Rules []SecretRule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse secret rules %s: %w", path, err)
+	}
+	for i, r := range file.Rules {
+		if r.ID == "" || r.Pattern == "" {
+			return nil, fmt.Errorf("secret rule %d in %s: id and pattern are required", i+1, path)
+		}
+	}
+	return file.Rules, nil
+}
+
+func mergeSecretRules(base, extra []SecretRule) []SecretRule {
+	index := make(map[string]int, len(base))
+	for i, r := range base {
+		index[r.ID] = i
+	}
+	for _, r := range extra {
+		if i, ok := index[r.ID]; ok {
+			base[i] = r
+			continue
+		}
+		index[r.ID] = len(base)
+		base = append(base, r)
+	}
+	return base
+}
+
+func (e *SecretsExtractor) Name() string { return "secrets" }
+
+func (e *SecretsExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem, error) {
+	html := page.RenderedHTML
+	if html == "" {
+		html = page.RawHTML
+	}
+	if html == "" {
+		return nil, nil
+	}
+
+	var items []plugin.ExtractedItem
+	seen := make(map[string]bool)
+	// A value matched by several rules is reported once, under the most
+	// specific rule (the generic rule comes last)
+	add := func(text string, found []secretMatch, all []secretMatch, location string) {
+		for _, m := range found {
+			if seen[m.secret] {
+				continue
+			}
+			seen[m.secret] = true
+			items = append(items, e.item(page.URL, location, m, e.context(text, m, all)))
+		}
+	}
+
+	// Inline scripts are reported separately from the surrounding markup
+	scripts := inlineScriptPattern.FindAllStringSubmatchIndex(html, -1)
+	inScript := func(offset int) bool {
+		for _, loc := range scripts {
+			if offset >= loc[2] && offset < loc[3] {
+				return true
+			}
+		}
+		return false
+	}
+	found := e.scan(html)
+	var inHTML, inInline []secretMatch
+	for _, m := range found {
+		if inScript(m.offset) {
+			inInline = append(inInline, m)
+		} else {
+			inHTML = append(inHTML, m)
+		}
+	}
+	add(html, inHTML, found, "html")
+	add(html, inInline, found, "inline_script")
+
+	if e.cfg.FetchScript != nil {
+		for _, src := range e.scriptURLs(page) {
+			body, err := e.cfg.FetchScript(src)
+			if err != nil {
+				continue
+			}
+			found := e.scan(body)
+			add(body, found, found, src)
+		}
+	}
+
+	return items, nil
+}
+
+var inlineScriptPattern = regexp.MustCompile(`(?is)<script\b[^>]*>(.*?)</script>`)
+
+// scriptURLs returns the page's external scripts that haven't been
+// scanned yet, claiming them so concurrent pages don't fetch them twice.
+func (e *SecretsExtractor) scriptURLs(page *plugin.PageData) []string {
+	doc, err := document(page)
+	if err != nil {
+		return nil
+	}
+	baseURL, _ := url.Parse(page.FinalURL)
+	if baseURL == nil {
+		baseURL, _ = url.Parse(page.URL)
+	}
+
+	var urls []string
+	doc.Find("script[src]").Each(func(_ int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		resolved := resolveURL(baseURL, strings.TrimSpace(src))
+		if !strings.HasPrefix(resolved, "http://") && !strings.HasPrefix(resolved, "https://") {
+			return
+		}
+		if e.cfg.InScope != nil && !e.cfg.InScope(resolved) {
+			return
+		}
+		e.mu.Lock()
+		claimed := !e.scanned[resolved]
+		e.scanned[resolved] = true
+		e.mu.Unlock()
+		if claimed {
+			urls = append(urls, resolved)
+		}
+	})
+	return urls
+}
+
+type secretMatch struct {
+	rule    *SecretRule
+	secret  string
+	offset  int // byte offsets of the secret in the scanned text
+	end     int
+	entropy float64
+}
+
+// scan runs every rule over text.
+func (e *SecretsExtractor) scan(text string) []secretMatch {
+	var found []secretMatch
+	for i := range e.rules {
+		rule := &e.rules[i]
+		if len(rule.Keywords) > 0 && !containsAny(text, rule.Keywords) {
+			continue
+		}
+		for _, loc := range rule.re.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[2*rule.Group], loc[2*rule.Group+1]
+			if start < 0 {
+				continue
+			}
+			secret := text[start:end]
+			entropy := shannonEntropy(secret)
+			if rule.MinEntropy > 0 && entropy < rule.MinEntropy {
+				continue
+			}
+			found = append(found, secretMatch{
+				rule:    rule,
+				secret:  secret,
+				offset:  start,
+				end:     end,
+				entropy: entropy,
+			})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].offset < found[j].offset })
+	return found
+}
+
+func (e *SecretsExtractor) item(sourceURL, location string, m secretMatch, context string) plugin.ExtractedItem {
+	sum := sha256.Sum256([]byte(m.secret))
+	fingerprint := hex.EncodeToString(sum[:6])
+
+	// Fully redacted values stay distinguishable by their fingerprint
+	value := e.redact(m.secret)
+	if e.cfg.Redact == RedactFull {
+		value = fmt.Sprintf("[REDACTED %s %s]", m.rule.ID, fingerprint)
+	}
+
+	return plugin.ExtractedItem{
+		Type:      "secret",
+		Value:     value,
+		SourceURL: sourceURL,
+		Metadata: map[string]string{
+			"rule":        m.rule.ID,
+			"description": m.rule.Description,
+			"severity":    m.rule.Severity,
+			"location":    location,
+			"entropy":     strconv.FormatFloat(m.entropy, 'f', 2, 64),
+			"context":     context,
+			"fingerprint": fingerprint,
+		},
+	}
+}
+
+func (e *SecretsExtractor) redact(secret string) string {
+	switch e.cfg.Redact {
+	case RedactNone:
+		return secret
+	case RedactFull:
+		return "[REDACTED]"
+	}
+	if len(secret) <= 12 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", len(secret)-8) + secret[len(secret)-4:]
+}
+
+// shannonEntropy returns the entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	entropy := 0.0
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// context returns about 40 bytes of text either side of m on a single
+// line. Every secret overlapping the window is redacted, not just m.
+func (e *SecretsExtractor) context(text string, m secretMatch, all []secretMatch) string {
+	from := max(0, m.offset-40)
+	for from < m.offset && !utf8.RuneStart(text[from]) {
+		from++
+	}
+	to := min(len(text), m.end+40)
+	for to > m.end && to < len(text) && !utf8.RuneStart(text[to]) {
+		to--
+	}
+
+	var b strings.Builder
+	pos := from
+	for _, other := range all {
+		if other.end <= from || other.offset >= to || other.end <= pos {
+			continue
+		}
+		if other.offset > pos {
+			b.WriteString(text[pos:other.offset])
+		}
+		b.WriteString(e.redact(other.secret))
+		pos = other.end
+	}
+	if pos < to {
+		b.WriteString(text[pos:to])
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
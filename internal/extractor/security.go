@@ -0,0 +1,254 @@
+package extractor
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// minHSTSMaxAge is the shortest HSTS lifetime not flagged (180 days).
+const minHSTSMaxAge = 180 * 24 * 60 * 60
+
+// SecurityExtractor audits a page's response headers and cookies: CSP,
+// HSTS, X-Frame-Options, X-Content-Type-Options, Referrer-Policy,
+// Permissions-Policy, CORS and Set-Cookie flags. Each problem becomes a
+// "security" item tagged with a severity (high, medium, low or info).
+type SecurityExtractor struct{}
+
+func NewSecurityExtractor() *SecurityExtractor { return &SecurityExtractor{} }
+
+func (e *SecurityExtractor) Name() string { return "security" }
+
+func (e *SecurityExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem, error) {
+	// Browser-fetched pages carry no response headers to audit
+	if len(page.Headers) == 0 {
+		return nil, nil
+	}
+
+	pageURL := page.FinalURL
+	if pageURL == "" {
+		pageURL = page.URL
+	}
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, nil
+	}
+	a := &securityAudit{page: page, host: u.Hostname(), https: u.Scheme == "https"}
+	h := page.Headers
+
+	a.checkCSP(h)
+	if a.https {
+		a.checkHSTS(h.Get("Strict-Transport-Security"))
+	}
+	a.checkFraming(h)
+	if !strings.EqualFold(strings.TrimSpace(h.Get("X-Content-Type-Options")), "nosniff") {
+		a.add("xcto_missing", "low", "X-Content-Type-Options", "not set to nosniff; browsers may MIME-sniff responses")
+	}
+	a.checkReferrerPolicy(h.Get("Referrer-Policy"))
+	if h.Get("Permissions-Policy") == "" {
+		a.add("permissions_policy_missing", "info", "Permissions-Policy", "header missing; powerful browser features are not restricted")
+	}
+	a.checkCORS(h)
+	a.checkCookies(h)
+
+	return a.items, nil
+}
+
+// securityAudit collects the findings for one page.
+type securityAudit struct {
+	page  *plugin.PageData
+	host  string
+	https bool
+	items []plugin.ExtractedItem
+}
+
+func (a *securityAudit) add(issue, severity, header, detail string) {
+	a.items = append(a.items, plugin.ExtractedItem{
+		Type:      "security",
+		Value:     fmt.Sprintf("[%s] %s: %s", severity, header, detail),
+		SourceURL: a.page.URL,
+		Metadata: map[string]string{
+			"issue":    issue,
+			"severity": severity,
+			"header":   header,
+			"detail":   detail,
+			"host":     a.host,
+		},
+	})
+}
+
+func (a *securityAudit) checkCSP(h http.Header) {
+	csp := h.Get("Content-Security-Policy")
+	if csp == "" {
+		if h.Get("Content-Security-Policy-Report-Only") != "" {
+			a.add("csp_report_only", "low", "Content-Security-Policy", "only a report-only policy is set; nothing is enforced")
+		} else {
+			a.add("csp_missing", "medium", "Content-Security-Policy", "header missing")
+		}
+		return
+	}
+
+	directives := parseCSP(csp)
+	scripts, ok := directives["script-src"]
+	if !ok {
+		scripts, ok = directives["default-src"]
+	}
+	if !ok {
+		a.add("csp_no_script_src", "medium", "Content-Security-Policy", "neither script-src nor default-src restricts scripts")
+		return
+	}
+
+	// 'unsafe-inline' is ignored by browsers once a nonce or hash is present
+	hasNonce := false
+	for _, src := range scripts {
+		if strings.HasPrefix(src, "'nonce-") || strings.HasPrefix(src, "'sha") {
+			hasNonce = true
+		}
+	}
+	for _, src := range scripts {
+		switch {
+		case src == "'unsafe-inline'" && !hasNonce:
+			a.add("csp_unsafe_inline", "medium", "Content-Security-Policy", "scripts allow 'unsafe-inline'")
+		case src == "'unsafe-eval'":
+			a.add("csp_unsafe_eval", "low", "Content-Security-Policy", "scripts allow 'unsafe-eval'")
+		case src == "*" || src == "http:" || src == "https:" || src == "data:":
+			a.add("csp_wildcard_source", "high", "Content-Security-Policy", "scripts may load from "+src)
+		}
+	}
+}
+
+// parseCSP splits a policy into directive name -> source list.
+func parseCSP(policy string) map[string][]string {
+	directives := make(map[string][]string)
+	for _, part := range strings.Split(policy, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if _, dup := directives[name]; dup {
+			continue // browsers honour the first occurrence only
+		}
+		var sources []string
+		for _, f := range fields[1:] {
+			sources = append(sources, strings.ToLower(f))
+		}
+		directives[name] = sources
+	}
+	return directives
+}
+
+func (a *securityAudit) checkHSTS(value string) {
+	if value == "" {
+		a.add("hsts_missing", "medium", "Strict-Transport-Security", "header missing on an HTTPS page")
+		return
+	}
+	maxAge := -1
+	subdomains := false
+	for _, part := range strings.Split(value, ";") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch strings.ToLower(strings.TrimSpace(k)) {
+		case "max-age":
+			if n, err := strconv.Atoi(strings.Trim(strings.TrimSpace(v), `"`)); err == nil {
+				maxAge = n
+			}
+		case "includesubdomains":
+			subdomains = true
+		}
+	}
+	switch {
+	case maxAge < 0:
+		a.add("hsts_invalid", "medium", "Strict-Transport-Security", "max-age missing or malformed")
+	case maxAge < minHSTSMaxAge:
+		a.add("hsts_short_max_age", "low", "Strict-Transport-Security",
+			fmt.Sprintf("max-age=%d is shorter than 180 days", maxAge))
+	}
+	if maxAge > 0 && !subdomains {
+		a.add("hsts_no_subdomains", "info", "Strict-Transport-Security", "includeSubDomains not set")
+	}
+}
+
+// checkFraming accepts either X-Frame-Options or a CSP frame-ancestors
+// directive, which supersedes it.
+func (a *securityAudit) checkFraming(h http.Header) {
+	if _, ok := parseCSP(h.Get("Content-Security-Policy"))["frame-ancestors"]; ok {
+		return
+	}
+	xfo := strings.ToUpper(strings.TrimSpace(h.Get("X-Frame-Options")))
+	switch xfo {
+	case "DENY", "SAMEORIGIN":
+	case "":
+		a.add("xfo_missing", "medium", "X-Frame-Options", "header and CSP frame-ancestors missing; page can be framed (clickjacking)")
+	default:
+		a.add("xfo_invalid", "low", "X-Frame-Options", "unsupported value "+strconv.Quote(xfo))
+	}
+}
+
+func (a *securityAudit) checkReferrerPolicy(value string) {
+	// The last recognised token wins; a missing header means the browser
+	// default (strict-origin-when-cross-origin in current browsers)
+	tokens := strings.Split(value, ",")
+	policy := strings.ToLower(strings.TrimSpace(tokens[len(tokens)-1]))
+	switch policy {
+	case "":
+		a.add("referrer_policy_missing", "low", "Referrer-Policy", "header missing; older browsers leak full URLs cross-origin")
+	case "unsafe-url", "no-referrer-when-downgrade":
+		a.add("referrer_policy_unsafe", "low", "Referrer-Policy", policy+" sends full URLs to other origins")
+	}
+}
+
+func (a *securityAudit) checkCORS(h http.Header) {
+	origin := strings.TrimSpace(h.Get("Access-Control-Allow-Origin"))
+	if origin == "" {
+		return
+	}
+	credentials := strings.EqualFold(strings.TrimSpace(h.Get("Access-Control-Allow-Credentials")), "true")
+	switch {
+	case origin == "*" && credentials:
+		a.add("cors_wildcard_credentials", "high", "Access-Control-Allow-Origin", "* combined with Allow-Credentials: true")
+	case origin == "*":
+		a.add("cors_wildcard", "info", "Access-Control-Allow-Origin", "any origin may read this response")
+	case strings.EqualFold(origin, "null"):
+		a.add("cors_null_origin", "medium", "Access-Control-Allow-Origin", "trusts the null origin (sandboxed frames, file: URLs)")
+	}
+}
+
+func (a *securityAudit) checkCookies(h http.Header) {
+	resp := http.Response{Header: h}
+	for _, cookie := range resp.Cookies() {
+		header := "Set-Cookie " + cookie.Name
+		if a.https && !cookie.Secure {
+			a.add("cookie_no_secure", "medium", header, "Secure flag missing")
+		}
+		if !cookie.HttpOnly {
+			severity := "low"
+			if isSessionCookie(cookie.Name) {
+				severity = "medium"
+			}
+			a.add("cookie_no_httponly", severity, header, "HttpOnly flag missing")
+		}
+		switch cookie.SameSite {
+		case 0, http.SameSiteDefaultMode:
+			a.add("cookie_no_samesite", "low", header, "SameSite attribute missing")
+		case http.SameSiteNoneMode:
+			if !cookie.Secure {
+				a.add("cookie_samesite_none_insecure", "medium", header, "SameSite=None without Secure is rejected by browsers")
+			}
+		}
+	}
+}
+
+// isSessionCookie guesses from its name whether a cookie holds a session.
+func isSessionCookie(name string) bool {
+	name = strings.ToLower(name)
+	for _, hint := range []string{"sess", "sid", "auth", "token", "jwt", "login"} {
+		if strings.Contains(name, hint) {
+			return true
+		}
+	}
+	return false
+}
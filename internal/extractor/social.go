@@ -1,37 +1,165 @@
 package extractor
 
 import (
+	"fmt"
 	"net/url"
+	"os"
+	"regexp"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/ramkansal/gofang/pkg/plugin"
+	"gopkg.in/yaml.v3"
 )
 
-// SocialExtractor extracts social media links from a page.
+// SocialPlatform describes how to recognise a platform's profile links.
+type SocialPlatform struct {
+	Name  string   `yaml:"name"`
+	Hosts []string `yaml:"hosts"` // exact hosts; subdomains also match
+
+	// Profile is matched against the URL path (plus query); its first
+	// non-empty capture group is the handle. Named groups "handle" and
+	// "id" take precedence. Without it the first path segment is used.
+	Profile string `yaml:"profile"`
+
+	// Share lists extra path prefixes of share, intent and widget URLs,
+	// which are not profiles and are skipped.
+	Share []string `yaml:"share"`
+
+	profile *regexp.Regexp
+}
+
+// defaultSocialPlatforms are the built-in platforms. Mastodon lists the
+// largest instances only; others are added with a platforms file.
+var defaultSocialPlatforms = []SocialPlatform{
+	{Name: "twitter", Hosts: []string{"twitter.com", "x.com"},
+		Profile: `^/(?:#!/)?@?(?P<handle>[A-Za-z0-9_]{1,15})/?$|^/i/user/(?P<id>\d+)`,
+		Share:   []string{"/home", "/i/flow/", "/i/web/", "/search", "/hashtag/", "/login", "/signup", "/tos", "/privacy"}},
+	{Name: "facebook", Hosts: []string{"facebook.com", "fb.com", "fb.me"},
+		Profile: `^/profile\.php\?(?:.*&)?id=(?P<id>\d+)|^/(?:pages/[^/]+/(?P<id>\d+)|(?:pg/)?(?P<handle>[A-Za-z0-9.\-]+))/?$`,
+		Share:   []string{"/sharer", "/dialog/", "/plugins/", "/tr", "/login", "/policies", "/help"}},
+	{Name: "instagram", Hosts: []string{"instagram.com"},
+		Profile: `^/(?P<handle>[A-Za-z0-9_.]{1,30})/?$`,
+		Share:   []string{"/p/", "/reel/", "/explore/", "/accounts/"}},
+	{Name: "linkedin", Hosts: []string{"linkedin.com"},
+		Profile: `^/(?P<handle>(?:in|company|school|showcase)/[^/?#]+)`,
+		Share:   []string{"/shareArticle", "/sharing/", "/cws/share", "/feed/"}},
+	{Name: "github", Hosts: []string{"github.com"},
+		Profile: `^/(?P<handle>[A-Za-z0-9](?:[A-Za-z0-9-]{0,38}))/?$`,
+		Share:   []string{"/features", "/about", "/login", "/join", "/pricing", "/site/", "/sponsors/", "/marketplace"}},
+	{Name: "youtube", Hosts: []string{"youtube.com", "youtu.be"},
+		Profile: `^/channel/(?P<id>UC[\w-]{22})|^/@(?P<handle>[\w.\-]+)|^/(?:c|user)/(?P<handle>[^/?#]+)`,
+		Share:   []string{"/embed/", "/subscribe_embed", "/share"}},
+	{Name: "tiktok", Hosts: []string{"tiktok.com"},
+		Profile: `^/@(?P<handle>[\w.]+)`,
+		Share:   []string{"/embed", "/share"}},
+	{Name: "reddit", Hosts: []string{"reddit.com"},
+		Profile: `^/(?P<handle>(?:u|user|r)/[\w\-]+)`,
+		Share:   []string{"/submit"}},
+	{Name: "pinterest", Hosts: []string{"pinterest.com"},
+		Profile: `^/(?P<handle>[\w]+)/?$`,
+		Share:   []string{"/pin/", "/button/"}},
+	{Name: "discord", Hosts: []string{"discord.gg", "discord.com"},
+		Profile: `^/(?:invite/)?(?P<id>[\w-]+)/?$`,
+		Share:   []string{"/widget", "/api/", "/channels/"}},
+	{Name: "telegram", Hosts: []string{"t.me", "telegram.me"},
+		Profile: `^/(?P<handle>[A-Za-z][\w]{4,31})/?$`,
+		Share:   []string{"/share/"}},
+	{Name: "mastodon", Hosts: []string{"mastodon.social", "mastodon.online", "mstdn.social"},
+		Profile: `^/@(?P<handle>[\w.]+(?:@[\w.\-]+)?)`,
+		Share:   []string{"/authorize_interaction", "/interact/"}},
+	{Name: "threads", Hosts: []string{"threads.net", "threads.com"},
+		Profile: `^/@(?P<handle>[\w.]+)`},
+	{Name: "bluesky", Hosts: []string{"bsky.app"},
+		Profile: `^/profile/(?:(?P<id>did:[\w:.\-]+)|(?P<handle>[\w.\-]+))`},
+}
+
+// sharePaths are share, intent and widget endpoints common to many
+// platforms.
+var sharePaths = []string{"/share", "/intent/", "/widgets", "/compose", "/dialog/"}
+
+// SocialExtractor extracts social media profiles from links, JSON-LD
+// sameAs and twitter:site, with the platform's handle or ID. Share,
+// intent and widget URLs are skipped.
 type SocialExtractor struct {
-	platforms map[string][]string // platform name → URL patterns
+	platforms []SocialPlatform
 }
 
 func NewSocialExtractor() *SocialExtractor {
-	return &SocialExtractor{
-		platforms: map[string][]string{
-			"twitter":   {"twitter.com/", "x.com/"},
-			"facebook":  {"facebook.com/", "fb.com/", "fb.me/"},
-			"instagram": {"instagram.com/"},
-			"linkedin":  {"linkedin.com/"},
-			"github":    {"github.com/"},
-			"youtube":   {"youtube.com/", "youtu.be/"},
-			"tiktok":    {"tiktok.com/"},
-			"reddit":    {"reddit.com/"},
-			"pinterest": {"pinterest.com/"},
-			"discord":   {"discord.gg/", "discord.com/"},
-			"telegram":  {"t.me/", "telegram.me/"},
-			"mastodon":  {"mastodon.social/"},
-			"threads":   {"threads.net/"},
-			"bluesky":   {"bsky.app/"},
-		},
+	e := &SocialExtractor{}
+	if err := e.AddPlatforms(defaultSocialPlatforms); err != nil {
+		panic(err) // the built-in patterns are fixed
+	}
+	return e
+}
+
+// AddPlatforms adds platforms to the extractor. A platform with the name
+// of an existing one adds hosts and share paths to it and, if set,
+// replaces its profile pattern.
+func (e *SocialExtractor) AddPlatforms(platforms []SocialPlatform) error {
+	for _, p := range platforms {
+		p.Name = strings.ToLower(strings.TrimSpace(p.Name))
+		if p.Name == "" {
+			return fmt.Errorf("social platform without a name")
+		}
+		hosts := make([]string, len(p.Hosts))
+		for i, h := range p.Hosts {
+			hosts[i] = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(h)), "www.")
+		}
+		var profile *regexp.Regexp
+		if p.Profile != "" {
+			re, err := regexp.Compile(p.Profile)
+			if err != nil {
+				return fmt.Errorf("social platform %s: profile: %w", p.Name, err)
+			}
+			profile = re
+		}
+
+		existing := e.platform(p.Name)
+		if existing == nil {
+			if len(hosts) == 0 {
+				return fmt.Errorf("social platform %s: hosts are required", p.Name)
+			}
+			p.Hosts, p.profile = hosts, profile
+			p.Share = append([]string(nil), p.Share...)
+			e.platforms = append(e.platforms, p)
+			continue
+		}
+		existing.Hosts = append(existing.Hosts, hosts...)
+		existing.Share = append(existing.Share, p.Share...)
+		if profile != nil {
+			existing.Profile, existing.profile = p.Profile, profile
+		}
+	}
+	return nil
+}
+
+func (e *SocialExtractor) platform(name string) *SocialPlatform {
+	for i := range e.platforms {
+		if e.platforms[i].Name == name {
+			return &e.platforms[i]
+		}
 	}
+	return nil
+}
+
+// LoadSocialPlatforms reads extra platforms from a YAML file with a
+// top-level "platforms" list.
+func LoadSocialPlatforms(path string) ([]SocialPlatform, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read social platforms: %w", err)
+	}
+	var file struct {
+		Platforms []SocialPlatform `yaml:"platforms"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse social platforms %s: %w", path, err)
+	}
+	if len(file.Platforms) == 0 {
+		return nil, fmt.Errorf("%s: no platforms defined", path)
+	}
+	return file.Platforms, nil
 }
 
 func (e *SocialExtractor) Name() string { return "social" }
@@ -45,7 +173,7 @@ func (e *SocialExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem
 		return nil, nil
 	}
 
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	doc, err := document(page)
 	if err != nil {
 		return nil, err
 	}
@@ -58,40 +186,198 @@ func (e *SocialExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem
 	seen := make(map[string]bool)
 	var items []plugin.ExtractedItem
 
+	add := func(raw, source, text string) {
+		profile, ok := e.match(raw)
+		if !ok {
+			return
+		}
+		// The same account linked as twitter.com and x.com is one profile
+		key := profile.url
+		if profile.handle != "" || profile.id != "" {
+			key = profile.platform + ":" + strings.ToLower(profile.handle) + ":" + profile.id
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		meta := map[string]string{
+			"platform": profile.platform,
+			"source":   source,
+		}
+		if profile.handle != "" {
+			meta["handle"] = profile.handle
+		}
+		if profile.id != "" {
+			meta["id"] = profile.id
+		}
+		if text != "" {
+			meta["anchor_text"] = truncate(text, 200)
+		}
+		items = append(items, plugin.ExtractedItem{
+			Type:      "social",
+			Value:     profile.url,
+			SourceURL: page.URL,
+			Metadata:  meta,
+		})
+	}
+
 	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
 		href, exists := s.Attr("href")
 		if !exists || href == "" {
 			return
 		}
+		if resolved := resolveURL(baseURL, strings.TrimSpace(href)); resolved != "" {
+			add(resolved, "link", strings.TrimSpace(s.Text()))
+		}
+	})
+
+	// Organization/Person sameAs in JSON-LD
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		for _, ent := range parseJSONLD(s.Text()) {
+			for _, link := range sameAs(ent) {
+				add(link, "json-ld", "")
+			}
+		}
+	})
 
-		resolved := resolveURL(baseURL, strings.TrimSpace(href))
-		if resolved == "" || seen[resolved] {
+	// Twitter card account
+	doc.Find(`meta[name="twitter:site"], meta[property="twitter:site"]`).Each(func(_ int, s *goquery.Selection) {
+		handle := strings.TrimPrefix(strings.TrimSpace(s.AttrOr("content", "")), "@")
+		if handle == "" {
 			return
 		}
+		if !strings.Contains(handle, "/") {
+			handle = "https://x.com/" + handle
+		}
+		add(handle, "twitter:site", "")
+	})
 
-		lower := strings.ToLower(resolved)
-		for platform, patterns := range e.platforms {
-			for _, pattern := range patterns {
-				if strings.Contains(lower, pattern) {
-					seen[resolved] = true
-					text := strings.TrimSpace(s.Text())
-					meta := map[string]string{
-						"platform": platform,
-					}
-					if text != "" {
-						meta["anchor_text"] = truncate(text, 200)
+	return items, nil
+}
+
+// socialProfile is a link recognised as a profile.
+type socialProfile struct {
+	platform string
+	url      string
+	handle   string
+	id       string
+}
+
+// match reports whether raw is a profile URL on a known platform. Hosts
+// match exactly or as a parent domain, so "x.com" matches "x.com" and
+// "mobile.x.com" but not "netflix.com".
+func (e *SocialExtractor) match(raw string) (socialProfile, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return socialProfile{}, false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+
+	for i := range e.platforms {
+		p := &e.platforms[i]
+		if !hostMatches(host, p.Hosts) {
+			continue
+		}
+		path := u.EscapedPath()
+		if isSharePath(path, p.Share) {
+			return socialProfile{}, false
+		}
+
+		profile := socialProfile{platform: p.Name, url: raw}
+		target := path
+		if u.RawQuery != "" {
+			target += "?" + u.RawQuery
+		}
+		if u.Fragment != "" && strings.HasPrefix(u.Fragment, "!/") {
+			target = "/#" + u.Fragment // legacy twitter.com/#!/handle
+		}
+
+		switch {
+		case p.profile != nil:
+			m := p.profile.FindStringSubmatch(target)
+			if m == nil {
+				// A post, video or file on the platform, not a profile
+				return socialProfile{}, false
+			}
+			for j, name := range p.profile.SubexpNames() {
+				if m[j] == "" || j == 0 {
+					continue
+				}
+				switch name {
+				case "id":
+					profile.id = m[j]
+				case "handle", "":
+					if profile.handle == "" {
+						profile.handle = m[j]
 					}
-					items = append(items, plugin.ExtractedItem{
-						Type:      "social",
-						Value:     resolved,
-						SourceURL: page.URL,
-						Metadata:  meta,
-					})
-					return
 				}
 			}
+		default:
+			if seg := strings.Split(strings.Trim(path, "/"), "/")[0]; seg != "" {
+				profile.handle = strings.TrimPrefix(seg, "@")
+			}
 		}
-	})
+		if p.Name == "mastodon" && profile.handle != "" && !strings.Contains(profile.handle, "@") {
+			profile.handle += "@" + host // fediverse handles are user@instance
+		}
+		return profile, true
+	}
+	return socialProfile{}, false
+}
 
-	return items, nil
+func hostMatches(host string, hosts []string) bool {
+	for _, h := range hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSharePath(path string, extra []string) bool {
+	lower := strings.ToLower(path)
+	for _, list := range [][]string{sharePaths, extra} {
+		for _, prefix := range list {
+			prefix = strings.ToLower(prefix)
+			if !strings.HasPrefix(lower, prefix) {
+				continue
+			}
+			// Whole segments only: /share matches /share.php but not /shared
+			rest := lower[len(prefix):]
+			if rest == "" || strings.HasSuffix(prefix, "/") || strings.ContainsAny(rest[:1], "/.?") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameAs collects the sameAs URLs of an entity and its nested entities.
+func sameAs(v any) []string {
+	var out []string
+	switch t := v.(type) {
+	case entity:
+		for k, val := range t {
+			if k == "sameAs" {
+				switch links := val.(type) {
+				case string:
+					out = append(out, links)
+				case []any:
+					for _, l := range links {
+						if s, ok := l.(string); ok {
+							out = append(out, s)
+						}
+					}
+				}
+				continue
+			}
+			out = append(out, sameAs(val)...)
+		}
+	case []any:
+		for _, el := range t {
+			out = append(out, sameAs(el)...)
+		}
+	}
+	return out
 }
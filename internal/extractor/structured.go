@@ -0,0 +1,479 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ramkansal/gofang/pkg/plugin"
+	"golang.org/x/net/html"
+)
+
+// StructuredDataExtractor parses schema.org data embedded as JSON-LD,
+// Microdata (itemscope/itemprop) or RDFa (typeof/property) into entities.
+// Each top-level entity becomes a "structured_data" item whose value is the
+// entity as JSON, nested entities included.
+type StructuredDataExtractor struct{}
+
+func NewStructuredDataExtractor() *StructuredDataExtractor { return &StructuredDataExtractor{} }
+
+func (e *StructuredDataExtractor) Name() string { return "structured_data" }
+
+// entity is a schema.org node: "@type", optional "@id" and its properties.
+// Property values are strings, nested entities or slices of either.
+type entity map[string]any
+
+func (e *StructuredDataExtractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem, error) {
+	raw := page.RenderedHTML
+	if raw == "" {
+		raw = page.RawHTML
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	doc, err := document(page)
+	if err != nil {
+		return nil, err
+	}
+	baseURL, _ := url.Parse(page.FinalURL)
+	if baseURL == nil {
+		baseURL, _ = url.Parse(page.URL)
+	}
+
+	var items []plugin.ExtractedItem
+	seen := make(map[string]bool)
+	add := func(format string, ent entity) {
+		value, err := marshalEntity(ent)
+		if err != nil || seen[value] {
+			return
+		}
+		seen[value] = true
+
+		meta := map[string]string{
+			"format":      format,
+			"entity_type": strings.Join(entityTypes(ent), ","),
+		}
+		if id, ok := ent["@id"].(string); ok {
+			meta["id"] = id
+		}
+		for _, key := range []string{"name", "headline"} {
+			if name, ok := ent[key].(string); ok && name != "" {
+				meta["name"] = truncate(name, 200)
+				break
+			}
+		}
+		items = append(items, plugin.ExtractedItem{
+			Type:      "structured_data",
+			Value:     value,
+			SourceURL: page.URL,
+			Metadata:  meta,
+		})
+	}
+
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		for _, ent := range parseJSONLD(s.Text()) {
+			add("json-ld", ent)
+		}
+	})
+	for _, root := range doc.Nodes {
+		for _, ent := range parseMicrodata(root, baseURL) {
+			add("microdata", ent)
+		}
+		for _, ent := range parseRDFa(root, baseURL) {
+			add("rdfa", ent)
+		}
+	}
+
+	return items, nil
+}
+
+func marshalEntity(ent entity) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(ent); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// entityTypes returns the entity's types as short schema.org names.
+func entityTypes(ent entity) []string {
+	switch t := ent["@type"].(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []any:
+		var types []string
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// shortName reduces "http://schema.org/Product", "schema:Product" and
+// "https://schema.org/Product" to "Product".
+func shortName(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexAny(s, "/#"); i >= 0 && i < len(s)-1 {
+		return s[i+1:]
+	}
+	if i := strings.LastIndex(s, ":"); i >= 0 && i < len(s)-1 && !strings.Contains(s, "//") {
+		return s[i+1:]
+	}
+	return s
+}
+
+// ---------- JSON-LD ----------
+
+// parseJSONLD returns the top-level nodes of a JSON-LD block: the object
+// itself, each element of an array, or each node of an @graph.
+func parseJSONLD(text string) []entity {
+	var doc any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &doc); err != nil {
+		return nil
+	}
+
+	var out []entity
+	var collect func(v any)
+	collect = func(v any) {
+		switch t := v.(type) {
+		case []any:
+			for _, el := range t {
+				collect(el)
+			}
+		case map[string]any:
+			if graph, ok := t["@graph"]; ok {
+				collect(graph)
+				return
+			}
+			if ent, ok := normalizeJSONLD(t).(entity); ok {
+				out = append(out, ent)
+			}
+		}
+	}
+	collect(doc)
+	return out
+}
+
+// normalizeJSONLD drops @context and shortens @type names, recursively.
+func normalizeJSONLD(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		ent := make(entity, len(t))
+		for k, val := range t {
+			switch k {
+			case "@context":
+				continue
+			case "@type":
+				switch types := val.(type) {
+				case string:
+					ent[k] = shortName(types)
+				case []any:
+					var names []string
+					for _, x := range types {
+						if s, ok := x.(string); ok {
+							names = append(names, shortName(s))
+						}
+					}
+					ent[k] = names
+				}
+			default:
+				ent[k] = normalizeJSONLD(val)
+			}
+		}
+		return ent
+	case []any:
+		out := make([]any, len(t))
+		for i, el := range t {
+			out[i] = normalizeJSONLD(el)
+		}
+		return out
+	}
+	return v
+}
+
+// ---------- Microdata ----------
+
+// parseMicrodata returns every top-level item: elements with itemscope
+// that are not themselves a property of another item.
+func parseMicrodata(root *html.Node, base *url.URL) []entity {
+	var out []entity
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && hasAttr(n, "itemscope") {
+			if !hasAttr(n, "itemprop") {
+				out = append(out, microdataItem(root, n, base, make(map[*html.Node]bool)))
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return out
+}
+
+// microdataItem reads the item at n. visiting holds the items being read
+// further up the stack, so itemref cycles end instead of recursing forever.
+func microdataItem(root, n *html.Node, base *url.URL, visiting map[*html.Node]bool) entity {
+	visiting[n] = true
+	defer delete(visiting, n)
+
+	ent := entity{}
+	if t := attr(n, "itemtype"); t != "" {
+		var types []string
+		for _, f := range strings.Fields(t) {
+			types = append(types, shortName(f))
+		}
+		if len(types) == 1 {
+			ent["@type"] = types[0]
+		} else {
+			ent["@type"] = types
+		}
+	}
+	if id := attr(n, "itemid"); id != "" {
+		ent["@id"] = id
+	}
+
+	// Properties come from the item's subtree plus any itemref'd elements
+	var refs []*html.Node
+	seen := make(map[*html.Node]bool)
+	for _, id := range strings.Fields(attr(n, "itemref")) {
+		ref := findByID(root, id)
+		// The item itself or an ancestor would contain the item again
+		if ref == nil || ref == n || isAncestor(ref, n) || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		microdataProps(root, c, base, ent, visiting)
+	}
+	for _, ref := range refs {
+		microdataProps(root, ref, base, ent, visiting)
+	}
+	return ent
+}
+
+func microdataProps(root, n *html.Node, base *url.URL, ent entity, visiting map[*html.Node]bool) {
+	if n.Type != html.ElementNode {
+		return
+	}
+	microdataProp(root, n, base, ent, visiting)
+	if hasAttr(n, "itemscope") {
+		return // its descendants belong to the nested item
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		microdataProps(root, c, base, ent, visiting)
+	}
+}
+
+func microdataProp(root, n *html.Node, base *url.URL, ent entity, visiting map[*html.Node]bool) {
+	names := strings.Fields(attr(n, "itemprop"))
+	if len(names) == 0 {
+		return
+	}
+	var value any
+	if hasAttr(n, "itemscope") {
+		if visiting[n] {
+			return // an item can't be its own property
+		}
+		value = microdataItem(root, n, base, visiting)
+	} else {
+		value = elementValue(n, base, "")
+	}
+	for _, name := range names {
+		addProp(ent, shortName(name), value)
+	}
+}
+
+// isAncestor reports whether a is a proper ancestor of n.
+func isAncestor(a, n *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p == a {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------- RDFa ----------
+
+// parseRDFa returns entities declared with typeof that are not the value
+// of another entity's property.
+func parseRDFa(root *html.Node, base *url.URL) []entity {
+	var out []entity
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && hasAttr(n, "typeof") && !hasAttr(n, "property") {
+			out = append(out, rdfaEntity(n, base))
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return out
+}
+
+func rdfaEntity(n *html.Node, base *url.URL) entity {
+	ent := entity{}
+	var types []string
+	for _, f := range strings.Fields(attr(n, "typeof")) {
+		types = append(types, shortName(f))
+	}
+	switch len(types) {
+	case 0:
+	case 1:
+		ent["@type"] = types[0]
+	default:
+		ent["@type"] = types
+	}
+	if id := firstAttr(n, "resource", "about"); id != "" {
+		ent["@id"] = id
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		rdfaProps(c, base, ent)
+	}
+	return ent
+}
+
+func rdfaProps(n *html.Node, base *url.URL, ent entity) {
+	if n.Type != html.ElementNode {
+		return
+	}
+	if names := strings.Fields(attr(n, "property")); len(names) > 0 {
+		var value any
+		if hasAttr(n, "typeof") {
+			value = rdfaEntity(n, base)
+		} else {
+			value = elementValue(n, base, "rdfa")
+		}
+		for _, name := range names {
+			addProp(ent, shortName(name), value)
+		}
+	}
+	if hasAttr(n, "typeof") {
+		return // a new entity starts here
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		rdfaProps(c, base, ent)
+	}
+}
+
+// ---------- helpers ----------
+
+// elementValue returns an element's property value following the
+// Microdata rules; RDFa additionally honours content and resource.
+func elementValue(n *html.Node, base *url.URL, syntax string) any {
+	if syntax == "rdfa" {
+		if v, ok := attrOK(n, "content"); ok {
+			return v
+		}
+		if v := attr(n, "resource"); v != "" {
+			return resolveURL(base, v)
+		}
+	}
+	switch n.Data {
+	case "meta":
+		return attr(n, "content")
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		return resolveURL(base, attr(n, "src"))
+	case "a", "area", "link":
+		return resolveURL(base, attr(n, "href"))
+	case "object":
+		return resolveURL(base, attr(n, "data"))
+	case "data", "meter":
+		return attr(n, "value")
+	case "time":
+		if v := attr(n, "datetime"); v != "" {
+			return v
+		}
+	}
+	if v, ok := attrOK(n, "content"); ok {
+		return v
+	}
+	return strings.Join(strings.Fields(textContent(n)), " ")
+}
+
+// addProp sets name on ent, turning repeated properties into a list.
+func addProp(ent entity, name string, value any) {
+	existing, ok := ent[name]
+	if !ok {
+		ent[name] = value
+		return
+	}
+	if list, ok := existing.([]any); ok {
+		ent[name] = append(list, value)
+		return
+	}
+	ent[name] = []any{existing, value}
+}
+
+func attrOK(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return strings.TrimSpace(a.Val), true
+		}
+	}
+	return "", false
+}
+
+func attr(n *html.Node, key string) string {
+	v, _ := attrOK(n, key)
+	return v
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	_, ok := attrOK(n, key)
+	return ok
+}
+
+func firstAttr(n *html.Node, keys ...string) string {
+	for _, k := range keys {
+		if v := attr(n, k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style"):
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func findByID(n *html.Node, id string) *html.Node {
+	if n.Type == html.ElementNode && attr(n, "id") == id {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findByID(c, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package fetcher
 import (
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
@@ -17,6 +18,10 @@ type BrowserFetcher struct {
 	timeout     time.Duration
 	pageTimeout time.Duration
 	userAgent   string
+	interaction InteractionConfig
+	capture     *capturer
+	jar         http.CookieJar
+	recorder    Recorder
 }
 
 // BrowserFetcherConfig holds configuration for the browser fetcher.
@@ -25,10 +30,23 @@ type BrowserFetcherConfig struct {
 	PageTimeout time.Duration
 	UserAgent   string
 	Headless    bool
+	Interaction InteractionConfig
+	Capture     CaptureConfig
+	CookieJar   http.CookieJar
+	Recorder    Recorder
 }
 
 // NewBrowserFetcher creates a new Rod-based browser fetcher.
 func NewBrowserFetcher(cfg BrowserFetcherConfig) (*BrowserFetcher, error) {
+	var capt *capturer
+	if cfg.Capture.Screenshots || cfg.Capture.PDF {
+		c, err := newCapturer(cfg.Capture)
+		if err != nil {
+			return nil, err
+		}
+		capt = c
+	}
+
 	u, err := launcher.New().
 		Headless(true).
 		Set("no-sandbox").
@@ -58,6 +76,10 @@ func NewBrowserFetcher(cfg BrowserFetcherConfig) (*BrowserFetcher, error) {
 		timeout:     timeout,
 		pageTimeout: pageTimeout,
 		userAgent:   cfg.UserAgent,
+		interaction: cfg.Interaction,
+		capture:     capt,
+		jar:         cfg.CookieJar,
+		recorder:    cfg.Recorder,
 	}, nil
 }
 
@@ -94,6 +116,7 @@ func (f *BrowserFetcher) Fetch(targetURL string, depth int) (*plugin.PageData, e
 
 	// Set up request interception for XHR/API capture
 	var intercepted []plugin.InterceptedRequest
+	var interceptMu sync.Mutex
 	router := rodPage.HijackRequests()
 	defer router.Stop()
 
@@ -108,6 +131,8 @@ func (f *BrowserFetcher) Fetch(targetURL string, depth int) (*plugin.PageData, e
 
 		// Only capture XHR/Fetch and document requests
 		if isWorthCapturing(resourceType) {
+			interceptMu.Lock()
+			defer interceptMu.Unlock()
 			intercepted = append(intercepted, plugin.InterceptedRequest{
 				URL:          reqURL,
 				Method:       method,
@@ -119,6 +144,22 @@ func (f *BrowserFetcher) Fetch(targetURL string, depth int) (*plugin.PageData, e
 
 	go router.Run()
 
+	// Record client-side routes (History API and hash changes)
+	_ = installSPAHooks(rodPage)
+
+	// Record every network exchange for HAR export
+	if f.recorder != nil {
+		netlog := startNetLog(f.recorder, rodPage)
+		defer netlog.flush()
+	}
+
+	// Follow the document's redirect chain
+	stopRedirects := watchRedirects(rodPage)
+	defer stopRedirects()
+
+	// Share the session with the HTTP fetcher
+	_ = loadCookies(f.jar, rodPage, targetURL)
+
 	// Navigate to the target URL
 	err = rodPage.Navigate(targetURL)
 	if err != nil {
@@ -142,6 +183,27 @@ func (f *BrowserFetcher) Fetch(targetURL string, depth int) (*plugin.PageData, e
 	if err == nil {
 		page.FinalURL = info.URL
 	}
+	page.Redirects = stopRedirects()
+
+	routes := collectSPARoutes(rodPage, page.FinalURL)
+
+	// Scroll and click through the page to reveal lazy-loaded content
+	if f.interaction.Enabled {
+		xhrs := func() []string {
+			interceptMu.Lock()
+			defer interceptMu.Unlock()
+			urls := make([]string, len(intercepted))
+			for i, r := range intercepted {
+				urls[i] = r.URL
+			}
+			return urls
+		}
+		page.Interactions = newInteractor(f.interaction, rodPage, xhrs).run(page.FinalURL)
+	}
+
+	page.SPARoutes = mergeUnique(routes, collectSPARoutes(rodPage, page.FinalURL))
+
+	_ = saveCookies(f.jar, rodPage, page.FinalURL)
 
 	// Get response status from navigation (best effort)
 	page.StatusCode = 200 // Default assumption for successful navigation
@@ -155,11 +217,20 @@ func (f *BrowserFetcher) Fetch(targetURL string, depth int) (*plugin.PageData, e
 		page.RawHTML = html
 	}
 
+	// Save screenshot / PDF of the rendered page
+	if f.capture.enabled() {
+		if err := f.capture.capture(rodPage, page); err != nil && page.Error == "" {
+			page.Error = err.Error()
+		}
+	}
+
 	// Get content type from the page
 	page.ContentType = "text/html"
 
 	// Store intercepted requests
-	page.InterceptedReqs = intercepted
+	interceptMu.Lock()
+	page.InterceptedReqs = append([]plugin.InterceptedRequest(nil), intercepted...)
+	interceptMu.Unlock()
 
 	page.FetchDuration = time.Since(start)
 	return page, nil
@@ -172,6 +243,21 @@ func (f *BrowserFetcher) Close() error {
 	return nil
 }
 
+// mergeUnique concatenates string slices, dropping duplicates.
+func mergeUnique(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, list := range lists {
+		for _, s := range list {
+			if !seen[s] {
+				seen[s] = true
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
 // isWorthCapturing determines if a request type is worth recording as an API endpoint.
 func isWorthCapturing(resourceType string) bool {
 	switch strings.ToLower(resourceType) {
@@ -0,0 +1,157 @@
+package fetcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HTTPCache is an on-disk store of fetched responses keyed by canonical URL.
+// Entries carry their validators so a recrawl can send conditional requests
+// and reuse the stored body on 304 Not Modified.
+type HTTPCache struct {
+	dir string
+}
+
+// cacheEntry is the stored form of a single response.
+type cacheEntry struct {
+	URL          string      `json:"url"`
+	FinalURL     string      `json:"final_url"`
+	StatusCode   int         `json:"status_code"`
+	Headers      http.Header `json:"headers"`
+	Body         []byte      `json:"body"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	StoredAt     time.Time   `json:"stored_at"`
+}
+
+// NewHTTPCache opens (creating if needed) a cache rooted at dir.
+func NewHTTPCache(dir string) (*HTTPCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &HTTPCache{dir: dir}, nil
+}
+
+// get returns the stored entry for rawURL, or nil if there is none.
+func (c *HTTPCache) get(rawURL string) *cacheEntry {
+	data, err := os.ReadFile(c.path(rawURL))
+	if err != nil {
+		return nil
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil
+	}
+	return &e
+}
+
+// put stores a response unless it forbids storage. Writes go through a
+// temporary file so concurrent readers never see a partial entry.
+func (c *HTTPCache) put(e *cacheEntry) error {
+	if strings.Contains(strings.ToLower(e.Headers.Get("Cache-Control")), "no-store") {
+		return nil
+	}
+	e.ETag = e.Headers.Get("ETag")
+	e.LastModified = e.Headers.Get("Last-Modified")
+	e.StoredAt = time.Now()
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	path := c.path(e.URL)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// conditionalHeaders returns the validators to send when revalidating e.
+func (e *cacheEntry) conditionalHeaders() http.Header {
+	h := make(http.Header)
+	if e.ETag != "" {
+		h.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		h.Set("If-Modified-Since", e.LastModified)
+	}
+	return h
+}
+
+// refresh merges the headers of a 304 response into the stored ones, as
+// RFC 9111 requires, keeping the original body.
+func (e *cacheEntry) refresh(h http.Header) {
+	for k, v := range h {
+		switch http.CanonicalHeaderKey(k) {
+		case "Content-Length", "Content-Encoding", "Transfer-Encoding":
+			continue
+		}
+		e.Headers[k] = v
+	}
+}
+
+// path shards entries by the first byte of the key hash.
+func (c *HTTPCache) path(rawURL string) string {
+	sum := sha256.Sum256([]byte(canonicalURL(rawURL)))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// canonicalURL normalises a URL for cache keys: lowercase scheme and host,
+// default ports and fragments dropped, query parameters sorted.
+func canonicalURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" &&
+		!(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+	u.Fragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		keys := make([]string, 0, len(q))
+		for k := range q {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var parts []string
+		for _, k := range keys {
+			vals := q[k]
+			sort.Strings(vals)
+			for _, v := range vals {
+				parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(v))
+			}
+		}
+		u.RawQuery = strings.Join(parts, "&")
+	}
+	return u.String()
+}
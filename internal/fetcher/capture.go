@@ -0,0 +1,171 @@
+package fetcher
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// CaptureConfig controls screenshot and PDF capture in browser mode.
+type CaptureConfig struct {
+	Dir         string
+	Screenshots bool
+	PDF         bool
+	Quality     int // JPEG quality 1-99; 0 or 100 keeps lossless PNG
+	MaxHeight   int // clip full-page screenshots to this many CSS pixels
+	MaxBytes    int // captures larger than this are discarded
+	MaxPDFPages int
+}
+
+// capturer saves visual evidence of rendered pages to disk.
+type capturer struct {
+	cfg CaptureConfig
+}
+
+func newCapturer(cfg CaptureConfig) (*capturer, error) {
+	if cfg.Dir == "" {
+		cfg.Dir = "captures"
+	}
+	if cfg.MaxHeight == 0 {
+		cfg.MaxHeight = 10000
+	}
+	if cfg.MaxBytes == 0 {
+		cfg.MaxBytes = 10 * 1024 * 1024
+	}
+	if cfg.MaxPDFPages == 0 {
+		cfg.MaxPDFPages = 20
+	}
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("create capture dir: %w", err)
+	}
+	return &capturer{cfg: cfg}, nil
+}
+
+func (c *capturer) enabled() bool {
+	return c != nil && (c.cfg.Screenshots || c.cfg.PDF)
+}
+
+// capture takes the configured screenshot and/or PDF of rodPage and records
+// the resulting file paths in page.
+func (c *capturer) capture(rodPage *rod.Page, page *plugin.PageData) error {
+	var errs []string
+
+	if c.cfg.Screenshots {
+		path, err := c.screenshot(rodPage, page.URL)
+		if err != nil {
+			errs = append(errs, "screenshot: "+err.Error())
+		}
+		page.ScreenshotPath = path
+	}
+
+	if c.cfg.PDF {
+		path, err := c.pdf(rodPage, page.URL)
+		if err != nil {
+			errs = append(errs, "pdf: "+err.Error())
+		}
+		page.PDFPath = path
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("capture failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (c *capturer) screenshot(rodPage *rod.Page, pageURL string) (string, error) {
+	metrics, err := proto.PageGetLayoutMetrics{}.Call(rodPage)
+	if err != nil {
+		return "", err
+	}
+	if metrics.CSSContentSize == nil {
+		return "", fmt.Errorf("failed to get css content size")
+	}
+
+	req := &proto.PageCaptureScreenshot{
+		Format: proto.PageCaptureScreenshotFormatPng,
+		Clip: &proto.PageViewport{
+			Width:  metrics.CSSContentSize.Width,
+			Height: math.Min(metrics.CSSContentSize.Height, float64(c.cfg.MaxHeight)),
+			Scale:  1,
+		},
+		CaptureBeyondViewport: true,
+	}
+	ext := ".png"
+	if c.cfg.Quality > 0 && c.cfg.Quality < 100 {
+		quality := c.cfg.Quality
+		req.Format = proto.PageCaptureScreenshotFormatJpeg
+		req.Quality = &quality
+		ext = ".jpg"
+	}
+
+	data, err := rodPage.Screenshot(false, req)
+	if err != nil {
+		return "", err
+	}
+	return c.save(pageURL, ext, data)
+}
+
+func (c *capturer) pdf(rodPage *rod.Page, pageURL string) (string, error) {
+	stream, err := rodPage.PDF(&proto.PagePrintToPDF{
+		PrintBackground: true,
+		PageRanges:      fmt.Sprintf("1-%d", c.cfg.MaxPDFPages),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	data, err := io.ReadAll(io.LimitReader(stream, int64(c.cfg.MaxBytes)+1))
+	if err != nil {
+		return "", err
+	}
+	return c.save(pageURL, ".pdf", data)
+}
+
+func (c *capturer) save(pageURL, ext string, data []byte) (string, error) {
+	if len(data) > c.cfg.MaxBytes {
+		return "", fmt.Errorf("%d bytes exceeds limit of %d", len(data), c.cfg.MaxBytes)
+	}
+	path := filepath.Join(c.cfg.Dir, captureFilename(pageURL)+ext)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// captureFilename builds a deterministic, filesystem-safe name for a URL:
+// a readable host/path prefix followed by a short hash of the full URL.
+func captureFilename(rawURL string) string {
+	sum := sha1.Sum([]byte(rawURL))
+	hash := hex.EncodeToString(sum[:])[:12]
+
+	readable := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		readable = u.Host + u.Path
+	}
+
+	var b strings.Builder
+	for _, r := range readable {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	name := strings.Trim(b.String(), "_")
+	if len(name) > 80 {
+		name = name[:80]
+	}
+	return name + "_" + hash
+}
@@ -0,0 +1,151 @@
+package fetcher
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ramkansal/gofang/internal/cookies"
+)
+
+// scopedJar is a jar that keeps each cookie's full scope, so host-only
+// cookies and the Secure/HttpOnly flags survive the trip through the
+// browser. *cookies.Jar implements it.
+type scopedJar interface {
+	http.CookieJar
+	Scoped(u *url.URL) []cookies.Cookie
+	Add(c cookies.Cookie)
+}
+
+// loadCookies copies cookies for targetURL from the shared jar into the browser page.
+func loadCookies(jar http.CookieJar, page *rod.Page, targetURL string) error {
+	if jar == nil {
+		return nil
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return err
+	}
+
+	sj, ok := jar.(scopedJar)
+	if !ok {
+		// Only name and value are known; scope them to this URL
+		var params []*proto.NetworkCookieParam
+		for _, c := range jar.Cookies(u) {
+			params = append(params, &proto.NetworkCookieParam{Name: c.Name, Value: c.Value, URL: targetURL})
+		}
+		if len(params) == 0 {
+			return nil
+		}
+		return page.SetCookies(params)
+	}
+
+	scoped := sj.Scoped(u)
+	if len(scoped) == 0 {
+		return nil
+	}
+	params := make([]*proto.NetworkCookieParam, 0, len(scoped))
+	for _, c := range scoped {
+		p := &proto.NetworkCookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			URL:      targetURL,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+		}
+		// Without a domain the browser scopes the cookie to the URL's
+		// host, which is what host-only means
+		if !c.HostOnly {
+			p.Domain = "." + c.Domain
+		}
+		if !c.Expires.IsZero() {
+			p.Expires = proto.TimeSinceEpoch(c.Expires.Unix())
+		}
+		switch c.SameSite {
+		case http.SameSiteStrictMode:
+			p.SameSite = proto.NetworkCookieSameSiteStrict
+		case http.SameSiteLaxMode:
+			p.SameSite = proto.NetworkCookieSameSiteLax
+		case http.SameSiteNoneMode:
+			p.SameSite = proto.NetworkCookieSameSiteNone
+		}
+		params = append(params, p)
+	}
+	return page.SetCookies(params)
+}
+
+// saveCookies copies the browser's cookies for targetURL back into the shared jar.
+func saveCookies(jar http.CookieJar, page *rod.Page, targetURL string) error {
+	if jar == nil {
+		return nil
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return err
+	}
+
+	browserCookies, err := page.Cookies([]string{targetURL})
+	if err != nil {
+		return err
+	}
+	sj, scoped := jar.(scopedJar)
+	httpCookies := make([]*http.Cookie, 0, len(browserCookies))
+	for _, c := range browserCookies {
+		// CDP marks domain cookies with a leading dot; host-only
+		// cookies carry the bare host
+		hostOnly := !strings.HasPrefix(c.Domain, ".")
+		var expires time.Time
+		if !c.Session && c.Expires > 0 {
+			expires = c.Expires.Time()
+		}
+		var sameSite http.SameSite
+		switch c.SameSite {
+		case proto.NetworkCookieSameSiteStrict:
+			sameSite = http.SameSiteStrictMode
+		case proto.NetworkCookieSameSiteLax:
+			sameSite = http.SameSiteLaxMode
+		case proto.NetworkCookieSameSiteNone:
+			sameSite = http.SameSiteNoneMode
+		}
+
+		if scoped {
+			sj.Add(cookies.Cookie{
+				Name:     c.Name,
+				Value:    c.Value,
+				Domain:   c.Domain,
+				Path:     c.Path,
+				HostOnly: hostOnly,
+				Secure:   c.Secure,
+				HttpOnly: c.HTTPOnly,
+				SameSite: sameSite,
+				Expires:  expires,
+			})
+			continue
+		}
+
+		hc := &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HTTPOnly,
+			SameSite: sameSite,
+			Expires:  expires,
+		}
+		if !hostOnly {
+			hc.Domain = c.Domain
+		}
+		if !expires.IsZero() && expires.Before(time.Now()) {
+			hc.MaxAge = -1
+		}
+		httpCookies = append(httpCookies, hc)
+	}
+	if len(httpCookies) > 0 {
+		jar.SetCookies(u, httpCookies)
+	}
+	return nil
+}
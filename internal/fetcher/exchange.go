@@ -0,0 +1,96 @@
+package fetcher
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// Exchange is a single request/response pair observed by a fetcher.
+type Exchange struct {
+	Fetcher         string
+	StartedAt       time.Time
+	Method          string
+	URL             string
+	Protocol        string
+	RequestHeaders  http.Header
+	RequestBody     []byte
+	StatusCode      int
+	StatusText      string
+	ResponseHeaders http.Header
+	ResponseBody    []byte
+	BodySize        int // bytes received on the wire, -1 if unknown
+	MimeType        string
+	RedirectURL     string
+	ResourceType    string
+	Error           string
+	Timings         Timings
+}
+
+// Timings breaks an exchange down into HAR phases. Phases that could not
+// be measured are negative.
+type Timings struct {
+	Blocked time.Duration
+	DNS     time.Duration
+	Connect time.Duration
+	SSL     time.Duration
+	Send    time.Duration
+	Wait    time.Duration
+	Receive time.Duration
+	Total   time.Duration
+}
+
+// unknownTiming marks a phase that was not measured.
+const unknownTiming = -time.Millisecond
+
+// Recorder receives every exchange a fetcher makes. Implementations must be
+// safe for concurrent use.
+type Recorder interface {
+	Record(ex *Exchange)
+}
+
+// exchangeFromColly converts a Colly response (or error response) into an Exchange.
+func exchangeFromColly(r *colly.Response, start time.Time, errMsg string) *Exchange {
+	total := time.Since(start)
+	ex := &Exchange{
+		Fetcher:      "http",
+		StartedAt:    start,
+		Method:       r.Request.Method,
+		URL:          r.Request.URL.String(),
+		Protocol:     "HTTP/1.1",
+		StatusCode:   r.StatusCode,
+		StatusText:   http.StatusText(r.StatusCode),
+		ResponseBody: r.Body,
+		BodySize:     len(r.Body),
+		ResourceType: "document",
+		Error:        errMsg,
+		Timings: Timings{
+			Blocked: unknownTiming,
+			DNS:     unknownTiming,
+			Connect: unknownTiming,
+			SSL:     unknownTiming,
+			Wait:    total,
+			Total:   total,
+		},
+	}
+	if r.Request.Headers != nil {
+		ex.RequestHeaders = r.Request.Headers.Clone()
+	}
+	if r.Headers != nil {
+		ex.ResponseHeaders = r.Headers.Clone()
+		ex.MimeType = r.Headers.Get("Content-Type")
+	}
+
+	if t := r.Trace; t != nil && t.FirstByteDuration > 0 {
+		connect := t.ConnectDuration
+		if connect > 0 {
+			ex.Timings.Connect = connect
+		} else {
+			connect = 0
+		}
+		ex.Timings.Wait = t.FirstByteDuration - connect
+		ex.Timings.Receive = total - t.FirstByteDuration
+	}
+	return ex
+}
@@ -2,6 +2,7 @@ package fetcher
 
 import (
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +15,9 @@ import (
 type HTTPFetcher struct {
 	collector *colly.Collector
 	userAgent string
+	noFollow  bool
+	recorder  Recorder
+	cache     *HTTPCache
 	mu        sync.Mutex
 	results   map[string]*plugin.PageData
 }
@@ -33,6 +37,9 @@ type HTTPFetcherConfig struct {
 	Proxy            string
 	CustomHeaders    []string
 	DisableRedirects bool
+	CookieJar        http.CookieJar
+	Recorder         Recorder
+	Cache            *HTTPCache
 }
 
 // NewHTTPFetcher creates a new Colly-based HTTP fetcher.
@@ -73,16 +80,25 @@ func NewHTTPFetcher(cfg HTTPFetcherConfig) *HTTPFetcher {
 		c.SetRequestTimeout(cfg.Timeout)
 	}
 
-	// Set proxy
+	// Record redirect hops on their way through; set proxy on the same transport
+	transport := http.DefaultTransport.(*http.Transport).Clone()
 	if cfg.Proxy != "" {
-		c.SetProxy(cfg.Proxy)
+		if proxyURL, err := url.Parse(cfg.Proxy); err == nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
 	}
+	c.WithTransport(&redirectTransport{base: transport})
 
 	// Set max response size
 	if cfg.MaxResponseSize > 0 {
 		c.MaxBodySize = cfg.MaxResponseSize
 	}
 
+	// Share cookies with the browser fetcher
+	if cfg.CookieJar != nil {
+		c.SetCookieJar(cfg.CookieJar)
+	}
+
 	// Disable redirects
 	if cfg.DisableRedirects {
 		c.SetRedirectHandler(func(req *http.Request, via []*http.Request) error {
@@ -105,6 +121,9 @@ func NewHTTPFetcher(cfg HTTPFetcherConfig) *HTTPFetcher {
 	f := &HTTPFetcher{
 		collector: c,
 		userAgent: cfg.UserAgent,
+		noFollow:  cfg.DisableRedirects,
+		recorder:  cfg.Recorder,
+		cache:     cfg.Cache,
 		results:   make(map[string]*plugin.PageData),
 	}
 
@@ -126,9 +145,32 @@ func (f *HTTPFetcher) Fetch(targetURL string, depth int) (*plugin.PageData, erro
 
 	// Clone the collector for this individual fetch so we get clean state
 	c := f.collector.Clone()
+	// The crawler frontier already de-duplicates URLs; allow refetches
+	// (e.g. after re-authentication)
+	c.AllowURLRevisit = true
+
+	var hops *hopLog
+	c.Context, hops = withHopLog(c.Context)
 
 	var fetchErr error
 
+	// Revalidate a cached copy instead of downloading it again
+	var cached *cacheEntry
+	notModified := false
+	if f.cache != nil {
+		if cached = f.cache.get(targetURL); cached != nil {
+			validators := cached.conditionalHeaders()
+			if len(validators) > 0 {
+				c.OnRequest(func(r *colly.Request) {
+					for k, v := range validators {
+						r.Headers.Set(k, v[0])
+					}
+				})
+			}
+		}
+		page.CacheStatus = "miss"
+	}
+
 	c.OnResponse(func(r *colly.Response) {
 		page.StatusCode = r.StatusCode
 		page.RawHTML = string(r.Body)
@@ -146,16 +188,71 @@ func (f *HTTPFetcher) Fetch(targetURL string, depth int) (*plugin.PageData, erro
 	})
 
 	c.OnError(func(r *colly.Response, err error) {
+		if r != nil && r.StatusCode == http.StatusNotModified && cached != nil {
+			notModified = true
+			if r.Headers != nil {
+				cached.refresh(*r.Headers)
+			}
+			return
+		}
 		fetchErr = err
 		if r != nil {
+			// Keep the error response so it can be archived and diffed
 			page.StatusCode = r.StatusCode
 			page.FinalURL = r.Request.URL.String()
+			page.RawHTML = string(r.Body)
+			page.ResponseSize = len(r.Body)
+			if r.Headers != nil {
+				page.ContentType = r.Headers.Get("Content-Type")
+				page.Headers = r.Headers.Clone()
+			}
 		}
 		page.Error = err.Error()
 	})
 
+	// Record the exchange for HAR export
+	if f.recorder != nil {
+		c.TraceHTTP = true
+		c.OnResponse(func(r *colly.Response) {
+			f.recorder.Record(exchangeFromColly(r, start, ""))
+		})
+		c.OnError(func(r *colly.Response, err error) {
+			if r != nil && r.Request != nil {
+				f.recorder.Record(exchangeFromColly(r, start, err.Error()))
+			}
+		})
+	}
+
 	// Perform the request
 	err := c.Visit(targetURL)
+	page.Redirects = hops.list()
+	if notModified {
+		// 304: serve the stored body so extractors still see the page
+		page.StatusCode = cached.StatusCode
+		page.FinalURL = cached.FinalURL
+		page.Headers = cached.Headers.Clone()
+		page.ContentType = cached.Headers.Get("Content-Type")
+		page.RawHTML = string(cached.Body)
+		page.ResponseSize = len(cached.Body)
+		page.CacheStatus = "hit"
+		page.FetchDuration = time.Since(start)
+		_ = f.cache.put(cached)
+		return page, nil
+	}
+	if chainErr := firstErr(err, fetchErr); chainErr != nil && len(page.Redirects) > 0 &&
+		(page.StatusCode == 0 || isRedirect(page.StatusCode)) {
+		// The chain was cut short (redirects disabled, a loop, too many hops
+		// or a disallowed host): report the last redirect as the page
+		last := page.Redirects[len(page.Redirects)-1]
+		page.StatusCode = last.StatusCode
+		page.FinalURL = last.URL
+		page.Error = ""
+		if !f.noFollow {
+			page.Error = chainErr.Error()
+		}
+		page.FetchDuration = time.Since(start)
+		return page, nil
+	}
 	if err != nil {
 		// Check if it's "already visited" — not really an error for us
 		if !strings.Contains(err.Error(), "already visited") {
@@ -173,9 +270,28 @@ func (f *HTTPFetcher) Fetch(targetURL string, depth int) (*plugin.PageData, erro
 		return page, fetchErr
 	}
 
+	if f.cache != nil && page.StatusCode >= 200 && page.StatusCode < 300 {
+		_ = f.cache.put(&cacheEntry{
+			URL:        targetURL,
+			FinalURL:   page.FinalURL,
+			StatusCode: page.StatusCode,
+			Headers:    page.Headers,
+			Body:       []byte(page.RawHTML),
+		})
+	}
+
 	return page, nil
 }
 
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (f *HTTPFetcher) Close() error {
 	return nil
 }
@@ -0,0 +1,276 @@
+package fetcher
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// InteractionConfig controls the optional interaction phase of the browser
+// fetcher, which scrolls and clicks through a page to reveal lazy content.
+type InteractionConfig struct {
+	Enabled        bool
+	MaxScrolls     int
+	MaxClicks      int
+	ActionDelay    time.Duration
+	ClickSelectors []string
+	DenyPatterns   []string
+}
+
+// DefaultClickSelectors are elements that usually reveal content without
+// leaving the page or changing server-side state.
+var DefaultClickSelectors = []string{
+	"button:not([type=submit])",
+	"[role=tab]",
+	"[role=button]",
+	"[aria-expanded=false]",
+	"summary",
+}
+
+// DefaultDenyPatterns guard against clicks with destructive side effects.
+// They are matched against the element's text, label, id, class and href.
+var DefaultDenyPatterns = []string{
+	`log\s*-?\s*out`, `sign\s*-?\s*out`, `sign\s*off`,
+	`delete`, `remove`, `destroy`, `\bdrop\b`, `erase`, `purge`,
+	`unsubscribe`, `deactivate`, `close\s+account`, `cancel`,
+	`buy`, `purchase`, `checkout`, `pay\b`, `order\s+now`,
+	`submit`, `send`, `reset`,
+}
+
+// interactor drives scrolling and clicking on a single rod page.
+type interactor struct {
+	cfg   InteractionConfig
+	deny  *regexp.Regexp
+	page  *rod.Page
+	xhrs  func() []string
+	links map[string]bool
+	steps []plugin.Interaction
+}
+
+func newInteractor(cfg InteractionConfig, page *rod.Page, xhrs func() []string) *interactor {
+	if cfg.MaxScrolls == 0 {
+		cfg.MaxScrolls = 10
+	}
+	if cfg.MaxClicks == 0 {
+		cfg.MaxClicks = 20
+	}
+	if cfg.ActionDelay == 0 {
+		cfg.ActionDelay = 500 * time.Millisecond
+	}
+	if len(cfg.ClickSelectors) == 0 {
+		cfg.ClickSelectors = DefaultClickSelectors
+	}
+	patterns := cfg.DenyPatterns
+	if len(patterns) == 0 {
+		patterns = DefaultDenyPatterns
+	}
+
+	return &interactor{
+		cfg:   cfg,
+		deny:  regexp.MustCompile(`(?i)(` + strings.Join(patterns, "|") + `)`),
+		page:  page,
+		xhrs:  xhrs,
+		links: make(map[string]bool),
+	}
+}
+
+// run performs the scroll phase followed by the click phase and returns
+// every action that was taken.
+func (in *interactor) run(pageURL string) []plugin.Interaction {
+	for _, l := range in.currentLinks() {
+		in.links[l] = true
+	}
+
+	in.scroll()
+	in.click(pageURL)
+
+	return in.steps
+}
+
+// scroll repeatedly scrolls to the bottom until the page stops growing.
+func (in *interactor) scroll() {
+	lastHeight := in.scrollHeight()
+	for i := 0; i < in.cfg.MaxScrolls; i++ {
+		seenXHRs := len(in.xhrs())
+		if _, err := in.page.Eval(`() => window.scrollTo(0, document.body.scrollHeight)`); err != nil {
+			return
+		}
+		in.settle()
+
+		step := in.record("scroll", "", seenXHRs)
+		height := in.scrollHeight()
+		if height <= lastHeight && len(step.NewLinks) == 0 && len(step.NewXHRs) == 0 {
+			return
+		}
+		lastHeight = height
+	}
+}
+
+// click clicks every safe element matching the configured selectors, up to
+// MaxClicks. If a click navigates away, the new URL is recorded and the
+// fetcher returns to the original page.
+func (in *interactor) click(pageURL string) {
+	clicked := 0
+	for _, selector := range in.cfg.ClickSelectors {
+		elements, err := in.page.Elements(selector)
+		if err != nil {
+			continue
+		}
+		for _, el := range elements {
+			if clicked >= in.cfg.MaxClicks {
+				return
+			}
+
+			desc, ok := in.describe(el)
+			if !ok {
+				continue
+			}
+
+			seenXHRs := len(in.xhrs())
+			if _, err := el.Eval(`() => this.click()`); err != nil {
+				continue
+			}
+			clicked++
+			in.settle()
+
+			step := in.record("click", desc, seenXHRs)
+
+			info, err := in.page.Info()
+			if err != nil || info.URL == pageURL {
+				continue
+			}
+			if !in.links[info.URL] {
+				in.links[info.URL] = true
+				step.NewLinks = append(step.NewLinks, info.URL)
+			}
+
+			// Clicking navigated away — go back. A hash change such as a
+			// #tab keeps the document, and reloading would undo what the
+			// click revealed.
+			if leftPage(pageURL, info.URL) {
+				if err := in.page.Navigate(pageURL); err != nil {
+					return
+				}
+				in.settle()
+				// Element handles are stale after navigation
+				break
+			}
+		}
+	}
+}
+
+// leftPage reports whether the browser moved from one URL to a different
+// document, i.e. the host or path changed.
+func leftPage(from, to string) bool {
+	a, err1 := url.Parse(from)
+	b, err2 := url.Parse(to)
+	if err1 != nil || err2 != nil {
+		return from != to
+	}
+	return a.Host != b.Host || a.Path != b.Path
+}
+
+// describe returns a short label for the element and whether it is safe to click.
+func (in *interactor) describe(el *rod.Element) (string, bool) {
+	res, err := el.Eval(`() => ({
+		visible: !!(this.offsetWidth || this.offsetHeight || this.getClientRects().length),
+		disabled: !!this.disabled,
+		text: (this.innerText || this.value || '').trim().slice(0, 100),
+		label: this.getAttribute('aria-label') || this.getAttribute('title') || '',
+		id: this.id || '',
+		cls: typeof this.className === 'string' ? this.className : '',
+		href: this.getAttribute('href') || '',
+		onclick: this.getAttribute('onclick') || '',
+		tag: this.tagName.toLowerCase(),
+		submits: this.type === 'submit' || this.type === 'reset' || !!this.form
+	})`)
+	if err != nil {
+		return "", false
+	}
+	v := res.Value
+	if !v.Get("visible").Bool() || v.Get("disabled").Bool() {
+		return "", false
+	}
+	// A button without a type submits its form, whatever its text says
+	if v.Get("submits").Bool() {
+		return "", false
+	}
+
+	haystack := strings.Join([]string{
+		v.Get("text").Str(), v.Get("label").Str(), v.Get("id").Str(),
+		v.Get("cls").Str(), v.Get("href").Str(), v.Get("onclick").Str(),
+	}, " ")
+	if in.deny.MatchString(haystack) {
+		return "", false
+	}
+
+	desc := v.Get("tag").Str()
+	if id := v.Get("id").Str(); id != "" {
+		desc += "#" + id
+	}
+	if text := v.Get("text").Str(); text != "" {
+		desc += " " + truncate(strings.Join(strings.Fields(text), " "), 60)
+	} else if label := v.Get("label").Str(); label != "" {
+		desc += " " + truncate(label, 60)
+	}
+	return desc, true
+}
+
+// record diffs links and XHRs against what has been seen so far and appends
+// a step for the action. It returns a pointer to the stored step.
+func (in *interactor) record(action, target string, seenXHRs int) *plugin.Interaction {
+	step := plugin.Interaction{Action: action, Target: target}
+
+	for _, l := range in.currentLinks() {
+		if !in.links[l] {
+			in.links[l] = true
+			step.NewLinks = append(step.NewLinks, l)
+		}
+	}
+	if xhrs := in.xhrs(); len(xhrs) > seenXHRs {
+		step.NewXHRs = append(step.NewXHRs, xhrs[seenXHRs:]...)
+	}
+
+	in.steps = append(in.steps, step)
+	return &in.steps[len(in.steps)-1]
+}
+
+func (in *interactor) currentLinks() []string {
+	res, err := in.page.Eval(`() => Array.from(document.querySelectorAll('a[href]'), a => a.href)`)
+	if err != nil {
+		return nil
+	}
+	var links []string
+	for _, v := range res.Value.Arr() {
+		if l := v.Str(); strings.HasPrefix(l, "http://") || strings.HasPrefix(l, "https://") {
+			links = append(links, l)
+		}
+	}
+	return links
+}
+
+func (in *interactor) scrollHeight() int {
+	res, err := in.page.Eval(`() => document.body ? document.body.scrollHeight : 0`)
+	if err != nil {
+		return 0
+	}
+	return res.Value.Int()
+}
+
+// settle gives the page time to react to an action.
+func (in *interactor) settle() {
+	time.Sleep(in.cfg.ActionDelay)
+	_ = in.page.WaitStable(in.cfg.ActionDelay)
+}
+
+// truncate limits a string to maxLen characters.
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen] + "..."
+}
@@ -0,0 +1,221 @@
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/gocolly/colly/v2"
+)
+
+// LoginForm describes a form-based login. Field values are CSS selectors;
+// a bare name such as "email" is treated as [name="email"].
+type LoginForm struct {
+	URL           string
+	UsernameField string
+	PasswordField string
+	SubmitButton  string
+	Username      string
+	Password      string
+}
+
+// Login submits the login form over plain HTTP. Hidden inputs such as CSRF
+// tokens are carried over from the login page; resulting cookies land in
+// the collector's jar.
+func (f *HTTPFetcher) Login(form LoginForm) error {
+	c := f.collector.Clone()
+	c.AllowURLRevisit = true
+
+	var (
+		body     []byte
+		finalURL string
+		reqErr   error
+	)
+	c.OnResponse(func(r *colly.Response) {
+		body = r.Body
+		finalURL = r.Request.URL.String()
+	})
+	c.OnError(func(r *colly.Response, err error) {
+		reqErr = err
+	})
+
+	if err := c.Visit(form.URL); err != nil {
+		return fmt.Errorf("load login page: %w", err)
+	}
+	c.Wait()
+	if reqErr != nil {
+		return fmt.Errorf("load login page: %w", reqErr)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+	if err != nil {
+		return fmt.Errorf("parse login page: %w", err)
+	}
+
+	userSel := fieldSelector(form.UsernameField)
+	passSel := fieldSelector(form.PasswordField)
+	passInput := doc.Find(passSel).First()
+	if passInput.Length() == 0 {
+		return fmt.Errorf("password field %q not found on %s", form.PasswordField, finalURL)
+	}
+	formSel := passInput.Closest("form")
+	if formSel.Length() == 0 {
+		return fmt.Errorf("password field %q is not inside a form", form.PasswordField)
+	}
+
+	// Start from the form's own defaults (hidden fields, CSRF tokens)
+	values := make(map[string]string)
+	formSel.Find("input[name], textarea[name], select[name]").Each(func(_ int, s *goquery.Selection) {
+		name, _ := s.Attr("name")
+		typ, _ := s.Attr("type")
+		switch strings.ToLower(typ) {
+		case "submit", "button", "image", "file", "reset":
+			return
+		case "checkbox", "radio":
+			if _, checked := s.Attr("checked"); !checked {
+				return
+			}
+		}
+		if goquery.NodeName(s) == "select" {
+			opt := s.Find("option[selected]").First()
+			if opt.Length() == 0 {
+				opt = s.Find("option").First()
+			}
+			values[name], _ = opt.Attr("value")
+			return
+		}
+		if goquery.NodeName(s) == "textarea" {
+			values[name] = s.Text()
+			return
+		}
+		values[name], _ = s.Attr("value")
+	})
+
+	userName, _ := formSel.Find(userSel).First().Attr("name")
+	passName, _ := passInput.Attr("name")
+	if userName == "" || passName == "" {
+		return errors.New("login fields must have a name attribute")
+	}
+	values[userName] = form.Username
+	values[passName] = form.Password
+
+	base, _ := url.Parse(finalURL)
+	action, _ := formSel.Attr("action")
+	actionURL := resolveAgainst(base, action)
+	method, _ := formSel.Attr("method")
+
+	body, reqErr = nil, nil
+	if strings.EqualFold(method, "get") {
+		u, err := url.Parse(actionURL)
+		if err != nil {
+			return err
+		}
+		q := u.Query()
+		for k, v := range values {
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+		err = c.Visit(u.String())
+		if err != nil {
+			return fmt.Errorf("submit login form: %w", err)
+		}
+	} else if err := c.Post(actionURL, values); err != nil {
+		return fmt.Errorf("submit login form: %w", err)
+	}
+	c.Wait()
+	if reqErr != nil {
+		return fmt.Errorf("submit login form: %w", reqErr)
+	}
+
+	// Still looking at a password prompt means the credentials were rejected
+	if after, err := goquery.NewDocumentFromReader(strings.NewReader(string(body))); err == nil {
+		if after.Find(passSel).Length() > 0 {
+			return errors.New("login rejected: password form is still present")
+		}
+	}
+	return nil
+}
+
+// Login fills and submits the login form in the headless browser, then
+// copies the resulting session cookies into the shared jar.
+func (f *BrowserFetcher) Login(form LoginForm) error {
+	rodPage, err := f.browser.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		return err
+	}
+	defer rodPage.Close()
+	rodPage = rodPage.Timeout(f.timeout)
+
+	if f.userAgent != "" {
+		_ = rodPage.SetUserAgent(&proto.NetworkSetUserAgentOverride{UserAgent: f.userAgent})
+	}
+	if err := rodPage.Navigate(form.URL); err != nil {
+		return fmt.Errorf("load login page: %w", err)
+	}
+	_ = rodPage.WaitStable(f.pageTimeout)
+
+	userEl, err := rodPage.Element(fieldSelector(form.UsernameField))
+	if err != nil {
+		return fmt.Errorf("username field %q: %w", form.UsernameField, err)
+	}
+	if err := userEl.Input(form.Username); err != nil {
+		return err
+	}
+	passEl, err := rodPage.Element(fieldSelector(form.PasswordField))
+	if err != nil {
+		return fmt.Errorf("password field %q: %w", form.PasswordField, err)
+	}
+	if err := passEl.Input(form.Password); err != nil {
+		return err
+	}
+
+	if form.SubmitButton != "" {
+		btn, err := rodPage.Element(form.SubmitButton)
+		if err != nil {
+			return fmt.Errorf("submit button %q: %w", form.SubmitButton, err)
+		}
+		if err := btn.Click(proto.InputMouseButtonLeft, 1); err != nil {
+			return err
+		}
+	} else if _, err := passEl.Eval(`() => this.form ? this.form.requestSubmit() : null`); err != nil {
+		return err
+	}
+
+	// Let the post-login redirect settle
+	time.Sleep(500 * time.Millisecond)
+	_ = rodPage.WaitStable(f.pageTimeout)
+
+	if has, _, _ := rodPage.Has(fieldSelector(form.PasswordField)); has {
+		return errors.New("login rejected: password form is still present")
+	}
+
+	info, err := rodPage.Info()
+	if err != nil {
+		return err
+	}
+	return saveCookies(f.jar, rodPage, info.URL)
+}
+
+// fieldSelector turns a bare field name into a CSS selector.
+func fieldSelector(field string) string {
+	if strings.ContainsAny(field, "[#.:= >") {
+		return field
+	}
+	return fmt.Sprintf(`[name=%q]`, field)
+}
+
+// resolveAgainst resolves ref against base, returning base itself for an empty ref.
+func resolveAgainst(base *url.URL, ref string) string {
+	if base == nil {
+		return ref
+	}
+	r, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return base.String()
+	}
+	return base.ResolveReference(r).String()
+}
@@ -0,0 +1,199 @@
+package fetcher
+
+import (
+	"encoding/base64"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// netLog follows CDP network events for one browser page and turns them
+// into Exchanges for a Recorder.
+type netLog struct {
+	recorder Recorder
+	page     *rod.Page
+	stop     func()
+
+	mu      sync.Mutex
+	pending map[proto.NetworkRequestID]*pendingExchange
+	bodies  sync.WaitGroup
+}
+
+type pendingExchange struct {
+	ex     *Exchange
+	sentAt proto.MonotonicTime
+	timing *proto.NetworkResourceTiming
+}
+
+// startNetLog subscribes to network events on page. Call flush before the
+// page is closed.
+func startNetLog(recorder Recorder, page *rod.Page) *netLog {
+	n := &netLog{
+		recorder: recorder,
+		page:     page,
+		pending:  make(map[proto.NetworkRequestID]*pendingExchange),
+	}
+
+	evPage, cancel := page.WithCancel()
+	n.stop = cancel
+	wait := evPage.EachEvent(
+		n.onRequest,
+		n.onResponse,
+		n.onFinished,
+		n.onFailed,
+	)
+	go wait()
+
+	return n
+}
+
+func (n *netLog) onRequest(e *proto.NetworkRequestWillBeSent) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// A redirect reuses the request ID; close out the previous hop first
+	if prev, ok := n.pending[e.RequestID]; ok && e.RedirectResponse != nil {
+		n.applyResponse(prev, e.RedirectResponse)
+		prev.ex.RedirectURL = e.Request.URL
+		n.finish(prev, e.Timestamp, 0)
+		delete(n.pending, e.RequestID)
+	}
+
+	ex := &Exchange{
+		Fetcher:        "browser",
+		StartedAt:      e.WallTime.Time(),
+		Method:         e.Request.Method,
+		URL:            e.Request.URL,
+		RequestHeaders: cdpHeaders(e.Request.Headers),
+		ResourceType:   string(e.Type),
+		BodySize:       -1,
+	}
+	if e.Request.PostData != "" {
+		ex.RequestBody = []byte(e.Request.PostData)
+	}
+	n.pending[e.RequestID] = &pendingExchange{ex: ex, sentAt: e.Timestamp}
+}
+
+func (n *netLog) onResponse(e *proto.NetworkResponseReceived) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if p, ok := n.pending[e.RequestID]; ok {
+		n.applyResponse(p, e.Response)
+	}
+}
+
+func (n *netLog) onFinished(e *proto.NetworkLoadingFinished) {
+	n.mu.Lock()
+	p, ok := n.pending[e.RequestID]
+	delete(n.pending, e.RequestID)
+	n.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	// Fetch the body off the event loop so other events keep flowing
+	n.bodies.Add(1)
+	go func() {
+		defer n.bodies.Done()
+		if res, err := (proto.NetworkGetResponseBody{RequestID: e.RequestID}).Call(n.page); err == nil {
+			if res.Base64Encoded {
+				p.ex.ResponseBody, _ = base64.StdEncoding.DecodeString(res.Body)
+			} else {
+				p.ex.ResponseBody = []byte(res.Body)
+			}
+		}
+		n.finish(p, e.Timestamp, int(e.EncodedDataLength))
+	}()
+}
+
+func (n *netLog) onFailed(e *proto.NetworkLoadingFailed) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if p, ok := n.pending[e.RequestID]; ok {
+		p.ex.Error = e.ErrorText
+		n.finish(p, e.Timestamp, 0)
+		delete(n.pending, e.RequestID)
+	}
+}
+
+// applyResponse copies response metadata onto a pending exchange.
+func (n *netLog) applyResponse(p *pendingExchange, resp *proto.NetworkResponse) {
+	p.ex.StatusCode = resp.Status
+	p.ex.StatusText = resp.StatusText
+	p.ex.ResponseHeaders = cdpHeaders(resp.Headers)
+	p.ex.MimeType = resp.MIMEType
+	p.ex.Protocol = resp.Protocol
+	if len(resp.RequestHeaders) > 0 {
+		// The network stack's view includes cookies and other late headers
+		p.ex.RequestHeaders = cdpHeaders(resp.RequestHeaders)
+	}
+	p.timing = resp.Timing
+}
+
+// finish computes timings and hands the exchange to the recorder.
+func (n *netLog) finish(p *pendingExchange, at proto.MonotonicTime, size int) {
+	ex := p.ex
+	if size > 0 {
+		ex.BodySize = size
+	}
+
+	total := (at - p.sentAt).Duration()
+	ex.Timings = Timings{
+		Blocked: unknownTiming,
+		DNS:     unknownTiming,
+		Connect: unknownTiming,
+		SSL:     unknownTiming,
+		Wait:    total,
+		Total:   total,
+	}
+
+	if t := p.timing; t != nil {
+		phase := func(start, end float64) time.Duration {
+			if start < 0 || end < 0 {
+				return unknownTiming
+			}
+			return time.Duration((end - start) * float64(time.Millisecond))
+		}
+		ex.Timings.DNS = phase(t.DNSStart, t.DNSEnd)
+		ex.Timings.Connect = phase(t.ConnectStart, t.ConnectEnd)
+		ex.Timings.SSL = phase(t.SslStart, t.SslEnd)
+		ex.Timings.Send = phase(t.SendStart, t.SendEnd)
+		ex.Timings.Wait = phase(t.SendEnd, t.ReceiveHeadersEnd)
+
+		headersDone := time.Duration((t.RequestTime*1000 + t.ReceiveHeadersEnd) * float64(time.Millisecond))
+		if recv := at.Duration() - headersDone; recv > 0 {
+			ex.Timings.Receive = recv
+		}
+	}
+
+	n.recorder.Record(ex)
+}
+
+// flush waits for outstanding body downloads, records anything still in
+// flight as incomplete and stops listening for events.
+func (n *netLog) flush() {
+	n.stop()
+	n.bodies.Wait()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for id, p := range n.pending {
+		p.ex.Error = "incomplete: page closed before the response finished"
+		n.finish(p, p.sentAt, 0)
+		delete(n.pending, id)
+	}
+}
+
+// cdpHeaders converts CDP headers into an http.Header.
+func cdpHeaders(h proto.NetworkHeaders) http.Header {
+	out := make(http.Header, len(h))
+	for k, v := range h {
+		out.Add(k, v.Str())
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// hopLogKey carries a *hopLog in a request context.
+type hopLogKey struct{}
+
+// hopLog collects the redirect responses seen while fetching one page.
+type hopLog struct {
+	mu   sync.Mutex
+	hops []plugin.RedirectHop
+}
+
+func withHopLog(ctx context.Context) (context.Context, *hopLog) {
+	log := &hopLog{}
+	return context.WithValue(ctx, hopLogKey{}, log), log
+}
+
+func (l *hopLog) add(hop plugin.RedirectHop) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hops = append(l.hops, hop)
+}
+
+func (l *hopLog) list() []plugin.RedirectHop {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]plugin.RedirectHop(nil), l.hops...)
+}
+
+// redirectTransport records every redirect response before the client
+// decides whether to follow it, so hops to disallowed hosts and cut-off
+// chains are still captured.
+type redirectTransport struct {
+	base http.RoundTripper
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if log, ok := req.Context().Value(hopLogKey{}).(*hopLog); ok && isRedirect(resp.StatusCode) {
+		if loc := resp.Header.Get("Location"); loc != "" {
+			log.add(plugin.RedirectHop{
+				URL:        req.URL.String(),
+				StatusCode: resp.StatusCode,
+				Location:   loc,
+				Duration:   time.Since(start),
+			})
+		}
+	}
+	return resp, nil
+}
+
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// watchRedirects follows the main document's redirects in a browser page.
+// The returned function stops listening and returns the hops.
+func watchRedirects(page *rod.Page) func() []plugin.RedirectHop {
+	var (
+		mu     sync.Mutex
+		docID  proto.NetworkRequestID
+		sentAt proto.MonotonicTime
+		hops   []plugin.RedirectHop
+	)
+
+	evPage, cancel := page.WithCancel()
+	wait := evPage.EachEvent(func(e *proto.NetworkRequestWillBeSent) {
+		if e.Type != proto.NetworkResourceTypeDocument {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+
+		// The navigation keeps one request ID across its redirects
+		if docID == "" {
+			docID = e.RequestID
+		}
+		if e.RequestID != docID {
+			return
+		}
+		if r := e.RedirectResponse; r != nil {
+			hops = append(hops, plugin.RedirectHop{
+				URL:        r.URL,
+				StatusCode: r.Status,
+				Location:   cdpHeaders(r.Headers).Get("Location"),
+				Duration:   (e.Timestamp - sentAt).Duration(),
+			})
+		}
+		sentAt = e.Timestamp
+	})
+	go wait()
+
+	return func() []plugin.RedirectHop {
+		cancel()
+		mu.Lock()
+		defer mu.Unlock()
+		return hops
+	}
+}
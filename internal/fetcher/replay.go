@@ -0,0 +1,405 @@
+package fetcher
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// maxReplayRedirects bounds how many archived redirects are followed.
+const maxReplayRedirects = 10
+
+// ReplayFetcher serves pages from a previously written WARC or HAR archive
+// instead of the network, so extractors can be re-run offline.
+type ReplayFetcher struct {
+	records map[string]*replayRecord
+	aliases map[string]string // requested URL -> archived URL
+	seed    string
+}
+
+type replayRecord struct {
+	url         string
+	statusCode  int
+	headers     http.Header
+	body        []byte
+	redirectURL string
+}
+
+// NewReplayFetcher loads every response from the archives matched by input.
+// input may be a single file, a comma-separated list or a glob; a WARC
+// prefix written with rotation (crawl.warc.gz -> crawl-00000.warc.gz, ...)
+// is expanded automatically.
+func NewReplayFetcher(input string) (*ReplayFetcher, error) {
+	files, err := resolveArchives(input)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &ReplayFetcher{
+		records: make(map[string]*replayRecord),
+		aliases: make(map[string]string),
+	}
+	for _, file := range files {
+		if err := f.load(file); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	if len(f.records) == 0 {
+		return nil, fmt.Errorf("no responses found in %s", input)
+	}
+	return f, nil
+}
+
+func (f *ReplayFetcher) Name() string { return "replay" }
+
+// Seed returns the first URL recorded in the archive.
+func (f *ReplayFetcher) Seed() string { return f.seed }
+
+// Has reports whether the archive holds a response for rawURL.
+func (f *ReplayFetcher) Has(rawURL string) bool {
+	return f.lookup(rawURL) != nil
+}
+
+func (f *ReplayFetcher) Fetch(targetURL string, depth int) (*plugin.PageData, error) {
+	start := time.Now()
+
+	page := &plugin.PageData{
+		URL:         targetURL,
+		FinalURL:    targetURL,
+		FetcherUsed: "replay",
+		FetchedAt:   start,
+		Depth:       depth,
+	}
+
+	rec := f.lookup(targetURL)
+	for hops := 0; rec != nil && rec.redirectURL != "" && hops < maxReplayRedirects; hops++ {
+		next := f.lookup(rec.redirectURL)
+		if next == nil {
+			break
+		}
+		rec = next
+	}
+	if rec == nil {
+		err := fmt.Errorf("%s is not in the archive", targetURL)
+		page.Error = err.Error()
+		page.FetchDuration = time.Since(start)
+		return page, err
+	}
+
+	page.FinalURL = rec.url
+	page.StatusCode = rec.statusCode
+	page.Headers = rec.headers.Clone()
+	page.ContentType = rec.headers.Get("Content-Type")
+	page.RawHTML = string(rec.body)
+	page.ResponseSize = len(rec.body)
+	page.FetchDuration = time.Since(start)
+	return page, nil
+}
+
+func (f *ReplayFetcher) Close() error {
+	return nil
+}
+
+// lookup finds a record by URL, tolerating trailing-slash differences.
+func (f *ReplayFetcher) lookup(rawURL string) *replayRecord {
+	key := replayKey(rawURL)
+	if alias, ok := f.aliases[key]; ok {
+		key = alias
+	}
+	return f.records[key]
+}
+
+func (f *ReplayFetcher) add(rec *replayRecord) {
+	key := replayKey(rec.url)
+	if f.seed == "" {
+		f.seed = rec.url
+	}
+	// Keep the first capture of a URL, like a crawl would have seen it
+	if _, exists := f.records[key]; !exists {
+		f.records[key] = rec
+	}
+}
+
+func (f *ReplayFetcher) alias(from, to string) {
+	if from != "" && to != "" && replayKey(from) != replayKey(to) {
+		f.aliases[replayKey(from)] = replayKey(to)
+	}
+}
+
+// load reads one archive, picking the format from its content.
+func (f *ReplayFetcher) load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	br := bufio.NewReader(file)
+	var r io.Reader = br
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	content := bufio.NewReader(r)
+	head, _ := content.Peek(16)
+	switch {
+	case bytes.HasPrefix(bytes.TrimSpace(head), []byte("WARC/")):
+		return f.loadWARC(content)
+	case bytes.HasPrefix(bytes.TrimSpace(head), []byte("{")):
+		return f.loadHAR(content)
+	default:
+		return fmt.Errorf("unrecognised archive format (expected WARC or HAR)")
+	}
+}
+
+// ---------- WARC ----------
+
+func (f *ReplayFetcher) loadWARC(r *bufio.Reader) error {
+	for {
+		headers, block, err := readWARCRecord(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := headers.Get("WARC-Target-URI")
+		switch headers.Get("WARC-Type") {
+		case "response":
+			if !strings.HasPrefix(headers.Get("Content-Type"), "application/http") {
+				continue
+			}
+			rec, err := parseHTTPBlock(target, block)
+			if err != nil {
+				continue
+			}
+			f.add(rec)
+		case "resource":
+			// A browser-rendered page: the DOM, without an HTTP response
+			rec := &replayRecord{
+				url:        target,
+				statusCode: http.StatusOK,
+				headers:    make(http.Header),
+				body:       block,
+			}
+			if ct := headers.Get("Content-Type"); ct != "" {
+				rec.headers.Set("Content-Type", ct)
+			}
+			f.add(rec)
+		case "metadata":
+			// gofang records the originally requested URL as "via"
+			for _, line := range strings.Split(string(block), "\n") {
+				if v, ok := strings.CutPrefix(strings.TrimSpace(line), "via: "); ok {
+					f.alias(v, target)
+				}
+			}
+		}
+	}
+}
+
+// readWARCRecord reads a single record: version line, named fields, block.
+func readWARCRecord(r *bufio.Reader) (http.Header, []byte, error) {
+	// Skip blank lines between records
+	var line string
+	for {
+		l, err := r.ReadString('\n')
+		if err != nil && l == "" {
+			return nil, nil, err
+		}
+		if line = strings.TrimSpace(l); line != "" {
+			break
+		}
+	}
+	if !strings.HasPrefix(line, "WARC/") {
+		return nil, nil, fmt.Errorf("expected WARC version line, got %q", line)
+	}
+
+	headers := make(http.Header)
+	for {
+		l, err := r.ReadString('\n')
+		if err != nil {
+			return nil, nil, err
+		}
+		l = strings.TrimRight(l, "\r\n")
+		if l == "" {
+			break
+		}
+		if name, value, ok := strings.Cut(l, ":"); ok {
+			headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+	}
+
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("record without valid Content-Length")
+	}
+	block := make([]byte, length)
+	if _, err := io.ReadFull(r, block); err != nil {
+		return nil, nil, err
+	}
+	return headers, block, nil
+}
+
+// parseHTTPBlock parses an archived HTTP response message.
+func parseHTTPBlock(target string, block []byte) (*replayRecord, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(block)), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body io.Reader = resp.Body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		if gz, err := gzip.NewReader(resp.Body); err == nil {
+			body = gz
+		}
+	}
+	data, err := io.ReadAll(body)
+	if err != nil && len(data) == 0 {
+		return nil, err
+	}
+
+	rec := &replayRecord{
+		url:        target,
+		statusCode: resp.StatusCode,
+		headers:    resp.Header,
+		body:       data,
+	}
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		rec.redirectURL = resolveAgainst(mustParse(target), resp.Header.Get("Location"))
+	}
+	return rec, nil
+}
+
+// ---------- HAR ----------
+
+type replayHAR struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				URL string `json:"url"`
+			} `json:"request"`
+			Response struct {
+				Status  int `json:"status"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				Content struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"content"`
+				RedirectURL string `json:"redirectURL"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+func (f *ReplayFetcher) loadHAR(r io.Reader) error {
+	var har replayHAR
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return err
+	}
+
+	for _, e := range har.Log.Entries {
+		if e.Response.Status == 0 {
+			continue
+		}
+		rec := &replayRecord{
+			url:        e.Request.URL,
+			statusCode: e.Response.Status,
+			headers:    make(http.Header),
+		}
+		for _, h := range e.Response.Headers {
+			rec.headers.Add(h.Name, h.Value)
+		}
+		if rec.headers.Get("Content-Type") == "" && e.Response.Content.MimeType != "" {
+			rec.headers.Set("Content-Type", e.Response.Content.MimeType)
+		}
+		if e.Response.Content.Encoding == "base64" {
+			rec.body, _ = base64.StdEncoding.DecodeString(e.Response.Content.Text)
+		} else {
+			rec.body = []byte(e.Response.Content.Text)
+		}
+		if e.Response.Status >= 300 && e.Response.Status < 400 {
+			loc := e.Response.RedirectURL
+			if loc == "" {
+				loc = rec.headers.Get("Location")
+			}
+			rec.redirectURL = resolveAgainst(mustParse(rec.url), loc)
+		}
+		f.add(rec)
+	}
+	return nil
+}
+
+// ---------- helpers ----------
+
+// resolveArchives expands input into the list of archive files to load.
+func resolveArchives(input string) ([]string, error) {
+	var files []string
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if _, err := os.Stat(part); err == nil {
+			files = append(files, part)
+			continue
+		}
+		matches, _ := filepath.Glob(part)
+		if len(matches) == 0 {
+			// Rotated WARC output: crawl.warc.gz -> crawl-*.warc.gz
+			prefix := strings.TrimSuffix(strings.TrimSuffix(part, ".gz"), ".warc")
+			matches, _ = filepath.Glob(prefix + "-[0-9][0-9][0-9][0-9][0-9].warc.gz")
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no archive found at %s", part)
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no archive given")
+	}
+	return files, nil
+}
+
+// replayKey normalises a URL for archive lookups.
+func replayKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Fragment = ""
+	u.Host = strings.ToLower(u.Host)
+	u.Path = strings.TrimRight(u.Path, "/")
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String()
+}
+
+func mustParse(rawURL string) *url.URL {
+	u, _ := url.Parse(rawURL)
+	return u
+}
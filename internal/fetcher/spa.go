@@ -0,0 +1,77 @@
+package fetcher
+
+import (
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+// spaHookJS is installed before any page script runs. It wraps the History
+// API and listens for hash changes so client-side navigations are recorded
+// in window.__gofangRoutes.
+const spaHookJS = `(() => {
+	if (window.__gofangRoutes) return;
+	const routes = window.__gofangRoutes = [];
+	const record = u => {
+		try { routes.push(new URL(String(u), location.href).href); } catch (e) {}
+	};
+	for (const fn of ['pushState', 'replaceState']) {
+		const orig = history[fn];
+		history[fn] = function (state, title, url) {
+			if (url !== undefined && url !== null) record(url);
+			return orig.apply(this, arguments);
+		};
+	}
+	window.addEventListener('hashchange', () => record(location.href));
+	window.addEventListener('popstate', () => record(location.href));
+})();`
+
+// spaCollectJS returns the recorded History API routes plus any route-like
+// attributes found on router link components in the current DOM.
+const spaCollectJS = `() => {
+	const out = new Set(window.__gofangRoutes || []);
+	const routeLike = /^(\/|#\/|#!|\.\.?\/|https?:)/i;
+	const add = v => {
+		if (!v) return;
+		v = v.trim();
+		if (!routeLike.test(v)) return;
+		try { out.add(new URL(v, location.href).href); } catch (e) {}
+	};
+	const attrs = ['routerlink', 'ng-reflect-router-link', 'to', 'data-href', 'data-url', 'data-route'];
+	for (const attr of attrs) {
+		document.querySelectorAll('[' + attr + ']').forEach(el => add(el.getAttribute(attr)));
+	}
+	const nav = /(?:location(?:\.href)?\s*=|location\.(?:assign|replace)\(|navigate(?:ByUrl)?\(|router\.push\()\s*['"` + "`" + `]([^'"` + "`" + `]+)['"` + "`" + `]/g;
+	document.querySelectorAll('[onclick]').forEach(el => {
+		const src = el.getAttribute('onclick') || '';
+		let m;
+		while ((m = nav.exec(src)) !== null) add(m[1]);
+	});
+	return Array.from(out);
+}`
+
+// installSPAHooks instruments the page so client-side routes are recorded.
+// It must be called before navigation.
+func installSPAHooks(page *rod.Page) error {
+	_, err := page.EvalOnNewDocument(spaHookJS)
+	return err
+}
+
+// collectSPARoutes returns the client-side routes discovered on the page,
+// excluding the page's own URL.
+func collectSPARoutes(page *rod.Page, pageURL string) []string {
+	res, err := page.Eval(spaCollectJS)
+	if err != nil {
+		return nil
+	}
+
+	var routes []string
+	for _, v := range res.Value.Arr() {
+		route := v.Str()
+		if route == "" || route == pageURL || strings.TrimSuffix(route, "#") == pageURL {
+			continue
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
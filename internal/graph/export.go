@@ -0,0 +1,177 @@
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteJSON writes the snapshot in the node-link layout d3-force expects.
+func (s *Snapshot) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(s)
+}
+
+// WriteDOT writes the snapshot as a Graphviz digraph. Node size follows
+// PageRank; uncrawled nodes are dashed and orphans are highlighted.
+func (s *Snapshot) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph crawl {\n")
+	b.WriteString("  graph [rankdir=LR, overlap=false];\n")
+	b.WriteString("  node [shape=box, fontsize=10];\n")
+
+	maxRank := 0.0
+	for _, n := range s.Nodes {
+		if n.PageRank > maxRank {
+			maxRank = n.PageRank
+		}
+	}
+
+	for _, n := range s.Nodes {
+		attrs := []string{
+			"label=" + dotQuote(n.URL),
+			fmt.Sprintf("tooltip=%s", dotQuote(fmt.Sprintf("status %d, in %d, out %d, pagerank %.4f", n.Status, n.InDegree, n.OutDegree, n.PageRank))),
+		}
+		if maxRank > 0 {
+			attrs = append(attrs, fmt.Sprintf("fontsize=%.1f", 8+8*n.PageRank/maxRank))
+		}
+		switch {
+		case !n.Crawled:
+			attrs = append(attrs, "style=dashed", "color=gray")
+		case n.Orphan:
+			attrs = append(attrs, "style=filled", "fillcolor=orange")
+		case n.Status >= 400:
+			attrs = append(attrs, "style=filled", "fillcolor=salmon")
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.URL), strings.Join(attrs, ", "))
+	}
+
+	for _, e := range s.Edges {
+		var attrs []string
+		if e.AnchorText != "" {
+			attrs = append(attrs, "label="+dotQuote(e.AnchorText))
+		}
+		if strings.Contains(e.Rel, "nofollow") {
+			attrs = append(attrs, "style=dotted")
+		}
+		line := fmt.Sprintf("  %s -> %s", dotQuote(e.Source), dotQuote(e.Target))
+		if len(attrs) > 0 {
+			line += " [" + strings.Join(attrs, ", ") + "]"
+		}
+		b.WriteString(line + ";\n")
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", " ")
+	return `"` + s + `"`
+}
+
+// ---------- GraphML ----------
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes the snapshot as GraphML, which Gephi, yEd and
+// NetworkX read directly.
+func (s *Snapshot) WriteGraphML(w io.Writer) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "url", For: "node", Name: "url", Type: "string"},
+			{ID: "crawled", For: "node", Name: "crawled", Type: "boolean"},
+			{ID: "status", For: "node", Name: "status", Type: "int"},
+			{ID: "depth", For: "node", Name: "depth", Type: "int"},
+			{ID: "in_degree", For: "node", Name: "in_degree", Type: "int"},
+			{ID: "out_degree", For: "node", Name: "out_degree", Type: "int"},
+			{ID: "pagerank", For: "node", Name: "pagerank", Type: "double"},
+			{ID: "orphan", For: "node", Name: "orphan", Type: "boolean"},
+			{ID: "anchor_text", For: "edge", Name: "anchor_text", Type: "string"},
+			{ID: "rel", For: "edge", Name: "rel", Type: "string"},
+			{ID: "edge_depth", For: "edge", Name: "depth", Type: "int"},
+		},
+		Graph: graphMLGraph{ID: "crawl", EdgeDefault: "directed"},
+	}
+
+	for _, n := range s.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: n.URL,
+			Data: []graphMLData{
+				{Key: "url", Value: n.URL},
+				{Key: "crawled", Value: strconv.FormatBool(n.Crawled)},
+				{Key: "status", Value: strconv.Itoa(n.Status)},
+				{Key: "depth", Value: strconv.Itoa(n.Depth)},
+				{Key: "in_degree", Value: strconv.Itoa(n.InDegree)},
+				{Key: "out_degree", Value: strconv.Itoa(n.OutDegree)},
+				{Key: "pagerank", Value: strconv.FormatFloat(n.PageRank, 'g', 8, 64)},
+				{Key: "orphan", Value: strconv.FormatBool(n.Orphan)},
+			},
+		})
+	}
+	for _, e := range s.Edges {
+		data := []graphMLData{{Key: "edge_depth", Value: strconv.Itoa(e.Depth)}}
+		if e.AnchorText != "" {
+			data = append(data, graphMLData{Key: "anchor_text", Value: e.AnchorText})
+		}
+		if e.Rel != "" {
+			data = append(data, graphMLData{Key: "rel", Value: e.Rel})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: e.Source, Target: e.Target, Data: data})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
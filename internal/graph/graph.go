@@ -0,0 +1,224 @@
+// Package graph builds the crawl's link graph and computes link metrics
+// (degrees, orphan pages, PageRank) for export to visualisation tools.
+package graph
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// PageRank parameters.
+const (
+	damping       = 0.85
+	maxIterations = 100
+	tolerance     = 1e-9
+)
+
+// Node is a URL in the graph. Pages that were linked to but never fetched
+// (external, out of depth, failed) are nodes with Crawled false.
+type Node struct {
+	URL       string  `json:"id"`
+	Crawled   bool    `json:"crawled"`
+	Status    int     `json:"status,omitempty"`
+	Depth     int     `json:"depth"`
+	InDegree  int     `json:"in_degree"`
+	OutDegree int     `json:"out_degree"`
+	PageRank  float64 `json:"pagerank"`
+	Orphan    bool    `json:"orphan,omitempty"`
+}
+
+// Edge is a link from one page to another.
+type Edge struct {
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+	AnchorText string `json:"anchor_text,omitempty"`
+	Rel        string `json:"rel,omitempty"`
+	Depth      int    `json:"depth"` // crawl depth of the source page
+}
+
+// Graph accumulates pages and links as the crawl progresses.
+type Graph struct {
+	normalize func(string) string
+
+	mu    sync.Mutex
+	nodes map[string]*Node
+	order []string // node URLs in first-seen order
+	edges []Edge
+	seen  map[[2]string]bool
+	seed  string
+}
+
+// New creates an empty graph. normalize maps link URLs onto the same form
+// the crawler uses for page URLs so edges meet their targets; nil keeps
+// URLs as they are.
+func New(normalize func(string) string) *Graph {
+	if normalize == nil {
+		normalize = func(s string) string { return s }
+	}
+	return &Graph{
+		normalize: normalize,
+		nodes:     make(map[string]*Node),
+		seen:      make(map[[2]string]bool),
+	}
+}
+
+// Add records a crawled page and its outgoing links. It is safe for
+// concurrent use.
+func (g *Graph) Add(result *plugin.CrawlResult) {
+	if result == nil || result.Page == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p := result.Page
+	src := g.node(p.URL)
+	src.Crawled = true
+	src.Status = p.StatusCode
+	src.Depth = p.Depth
+	if p.Depth == 0 && g.seed == "" {
+		g.seed = p.URL
+	}
+
+	for _, item := range result.ExtractedItems {
+		if item.Type != "link" {
+			continue
+		}
+		target := g.normalize(item.Value)
+		if target == "" || target == src.URL {
+			continue
+		}
+		key := [2]string{src.URL, target}
+		if g.seen[key] {
+			continue
+		}
+		g.seen[key] = true
+
+		dst := g.node(target)
+		if !dst.Crawled && dst.Depth == 0 {
+			dst.Depth = p.Depth + 1
+		}
+		g.edges = append(g.edges, Edge{
+			Source:     src.URL,
+			Target:     target,
+			AnchorText: item.Metadata["anchor_text"],
+			Rel:        item.Metadata["rel"],
+			Depth:      p.Depth,
+		})
+	}
+}
+
+func (g *Graph) node(url string) *Node {
+	n, ok := g.nodes[url]
+	if !ok {
+		n = &Node{URL: url}
+		g.nodes[url] = n
+		g.order = append(g.order, url)
+	}
+	return n
+}
+
+// Snapshot is a computed, read-only view of the graph.
+type Snapshot struct {
+	Nodes   []*Node  `json:"nodes"`
+	Edges   []Edge   `json:"links"`
+	Orphans []string `json:"orphans"`
+}
+
+// Compute derives degrees, orphan pages and PageRank and returns the
+// nodes sorted by URL.
+func (g *Graph) Compute() *Snapshot {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, n := range g.nodes {
+		n.InDegree, n.OutDegree = 0, 0
+	}
+	for _, e := range g.edges {
+		g.nodes[e.Source].OutDegree++
+		g.nodes[e.Target].InDegree++
+	}
+
+	// Orphans: crawled pages nothing else links to (reached via sitemaps,
+	// SPA routes or the seed list rather than a link)
+	snap := &Snapshot{Orphans: []string{}}
+	for _, url := range g.order {
+		n := g.nodes[url]
+		n.Orphan = n.Crawled && n.InDegree == 0 && url != g.seed
+		if n.Orphan {
+			snap.Orphans = append(snap.Orphans, url)
+		}
+	}
+
+	g.pageRank()
+
+	snap.Nodes = make([]*Node, 0, len(g.nodes))
+	for _, url := range g.order {
+		n := *g.nodes[url]
+		snap.Nodes = append(snap.Nodes, &n)
+	}
+	sort.Slice(snap.Nodes, func(i, j int) bool { return snap.Nodes[i].URL < snap.Nodes[j].URL })
+	sort.Strings(snap.Orphans)
+	snap.Edges = append([]Edge(nil), g.edges...)
+	return snap
+}
+
+// pageRank runs the power iteration; rank held by pages without outgoing
+// links is spread evenly over every node.
+func (g *Graph) pageRank() {
+	n := len(g.order)
+	if n == 0 {
+		return
+	}
+	index := make(map[string]int, n)
+	for i, url := range g.order {
+		index[url] = i
+	}
+	out := make([][]int, n)
+	for _, e := range g.edges {
+		s := index[e.Source]
+		out[s] = append(out[s], index[e.Target])
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+	next := make([]float64, n)
+	for iter := 0; iter < maxIterations; iter++ {
+		dangling := 0.0
+		for i := range next {
+			next[i] = 0
+			if len(out[i]) == 0 {
+				dangling += rank[i]
+			}
+		}
+		for i, targets := range out {
+			if len(targets) == 0 {
+				continue
+			}
+			share := rank[i] / float64(len(targets))
+			for _, t := range targets {
+				next[t] += share
+			}
+		}
+		delta := 0.0
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+		for i := range next {
+			next[i] = base + damping*next[i]
+			delta += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if delta < tolerance {
+			break
+		}
+	}
+
+	for i, url := range g.order {
+		g.nodes[url].PageRank = rank[i]
+	}
+}
@@ -0,0 +1,270 @@
+// Package linkcheck verifies every URL discovered during a crawl, including
+// external links and assets that are never crawled themselves.
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// maxRedirects bounds the redirect chain followed per URL.
+const maxRedirects = 10
+
+// Config controls how links are checked.
+type Config struct {
+	Concurrency   int // total requests in flight
+	PerHost       int // requests in flight per host
+	Timeout       time.Duration
+	UserAgent     string
+	Proxy         string
+	CustomHeaders []string
+	CookieJar     http.CookieJar
+}
+
+// Checker collects discovered URLs with the pages that refer to them and
+// checks each one once.
+type Checker struct {
+	cfg    Config
+	client *http.Client
+
+	mu      sync.Mutex
+	sources map[string][]string // URL -> referring pages
+	order   []string
+
+	hostMu sync.Mutex
+	hosts  map[string]chan struct{}
+}
+
+// New creates a Checker.
+func New(cfg Config) *Checker {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 10
+	}
+	if cfg.PerHost <= 0 {
+		cfg.PerHost = 2
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.Proxy != "" {
+		if u, err := url.Parse(cfg.Proxy); err == nil {
+			transport.Proxy = http.ProxyURL(u)
+		}
+	}
+
+	return &Checker{
+		cfg: cfg,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.Timeout,
+			Jar:       cfg.CookieJar,
+			// Hops are followed by hand so the chain can be recorded
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		sources: make(map[string][]string),
+		hosts:   make(map[string]chan struct{}),
+	}
+}
+
+// Add records that source refers to target. Only http(s) URLs are kept;
+// fragments are dropped.
+func (c *Checker) Add(target, source string) {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return
+	}
+	u.Fragment = ""
+	key := u.String()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	refs, ok := c.sources[key]
+	if !ok {
+		c.order = append(c.order, key)
+	}
+	for _, s := range refs {
+		if s == source {
+			return
+		}
+	}
+	c.sources[key] = append(refs, source)
+}
+
+// Len returns the number of distinct URLs collected.
+func (c *Checker) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.order)
+}
+
+// Run checks every collected URL, calling report as each one finishes, and
+// returns all results in discovery order. Cancelling ctx stops it early.
+func (c *Checker) Run(ctx context.Context, report func(*plugin.LinkStatus)) []*plugin.LinkStatus {
+	c.mu.Lock()
+	urls := append([]string(nil), c.order...)
+	c.mu.Unlock()
+
+	results := make([]*plugin.LinkStatus, len(urls))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < c.cfg.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				res := c.check(ctx, urls[i])
+				c.mu.Lock()
+				res.Sources = append([]string(nil), c.sources[urls[i]]...)
+				c.mu.Unlock()
+				sort.Strings(res.Sources)
+				results[i] = res
+				if report != nil {
+					report(res)
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range urls {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	var out []*plugin.LinkStatus
+	for _, r := range results {
+		if r != nil {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// check resolves one URL: HEAD first, then GET if the server rejects HEAD
+// or the HEAD request fails.
+func (c *Checker) check(ctx context.Context, target string) *plugin.LinkStatus {
+	res := c.follow(ctx, http.MethodHead, target)
+	if res.Error != "" || res.StatusCode >= 400 {
+		// Plenty of servers mishandle HEAD; only GET is authoritative
+		res = c.follow(ctx, http.MethodGet, target)
+	}
+	res.Broken = res.Error != "" || res.StatusCode >= 400
+	return res
+}
+
+// follow issues method against target and walks the redirect chain.
+func (c *Checker) follow(ctx context.Context, method, target string) *plugin.LinkStatus {
+	res := &plugin.LinkStatus{URL: target, Method: method}
+	seen := map[string]bool{}
+	current := target
+
+	for hop := 0; ; hop++ {
+		if hop > maxRedirects {
+			res.Error = fmt.Sprintf("more than %d redirects", maxRedirects)
+			return res
+		}
+		if seen[current] {
+			res.Error = "redirect loop"
+			return res
+		}
+		seen[current] = true
+
+		start := time.Now()
+		resp, err := c.do(ctx, method, current)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		elapsed := time.Since(start)
+		location := resp.Header.Get("Location")
+		resp.Body.Close()
+
+		res.StatusCode = resp.StatusCode
+		res.FinalURL = current
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+			return res
+		}
+
+		next, err := url.Parse(current)
+		if err == nil {
+			next, err = next.Parse(location)
+		}
+		if err != nil {
+			res.Error = "invalid Location: " + location
+			return res
+		}
+		res.Redirects = append(res.Redirects, plugin.RedirectHop{
+			URL:        current,
+			StatusCode: resp.StatusCode,
+			Location:   location,
+			Duration:   elapsed,
+		})
+		current = next.String()
+	}
+}
+
+// do sends one request while holding the host's slot.
+func (c *Checker) do(ctx context.Context, method, target string) (*http.Response, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	slot := c.hostSlot(u.Host)
+	select {
+	case slot <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-slot }()
+
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+	}
+	for _, h := range c.cfg.CustomHeaders {
+		if k, v, ok := strings.Cut(h, ":"); ok {
+			req.Header.Set(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodGet {
+		// Only the status matters; don't download large bodies
+		io.CopyN(io.Discard, resp.Body, 64*1024)
+	}
+	return resp, nil
+}
+
+func (c *Checker) hostSlot(host string) chan struct{} {
+	c.hostMu.Lock()
+	defer c.hostMu.Unlock()
+	slot, ok := c.hosts[host]
+	if !ok {
+		slot = make(chan struct{}, c.cfg.PerHost)
+		c.hosts[host] = slot
+	}
+	return slot
+}
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ramkansal/gofang/internal/graph"
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// GraphWriter builds the link graph from crawl results and exports it when
+// the crawl finishes. The format follows the file extension: .graphml,
+// .dot/.gv, or JSON for anything else.
+type GraphWriter struct {
+	path  string
+	graph *graph.Graph
+}
+
+// NewGraphWriter creates a writer for path. normalize must match the
+// crawler's URL normalisation so link targets meet crawled pages.
+func NewGraphWriter(path string, normalize func(string) string) *GraphWriter {
+	return &GraphWriter{path: path, graph: graph.New(normalize)}
+}
+
+func (w *GraphWriter) Name() string { return "graph" }
+
+func (w *GraphWriter) WriteResult(result *plugin.CrawlResult) error {
+	w.graph.Add(result)
+	return nil
+}
+
+// Finalize computes the graph metrics and writes the export.
+func (w *GraphWriter) Finalize(summary *plugin.CrawlSummary) error {
+	snap := w.graph.Compute()
+
+	f, err := os.Create(w.path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(w.path)) {
+	case ".graphml":
+		err = snap.WriteGraphML(f)
+	case ".dot", ".gv":
+		err = snap.WriteDOT(f)
+	default:
+		err = snap.WriteJSON(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,325 @@
+package output
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ramkansal/gofang/internal/fetcher"
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// HARWriter streams every request/response pair seen during a crawl into a
+// HAR 1.2 archive. Entries are written as they arrive, so memory use stays
+// flat regardless of crawl size.
+type HARWriter struct {
+	file        *os.File
+	buf         *bufio.Writer
+	maxBodySize int
+	count       int
+	err         error
+	mu          sync.Mutex
+}
+
+// NewHARWriter creates the archive at path and writes the HAR preamble.
+// Response bodies larger than maxBodySize bytes are truncated.
+func NewHARWriter(path string, maxBodySize int) (*HARWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &HARWriter{
+		file:        f,
+		buf:         bufio.NewWriter(f),
+		maxBodySize: maxBodySize,
+	}
+
+	creator, _ := json.Marshal(harCreator{Name: "gofang", Version: "1.0.0"})
+	fmt.Fprintf(w.buf, `{"log":{"version":"1.2","creator":%s,"entries":[`, creator)
+	return w, nil
+}
+
+func (w *HARWriter) Name() string { return "har" }
+
+// Record implements fetcher.Recorder.
+func (w *HARWriter) Record(ex *fetcher.Exchange) {
+	data, err := json.Marshal(w.entry(ex))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err != nil || w.err != nil || w.file == nil {
+		if w.err == nil {
+			w.err = err
+		}
+		return
+	}
+	if w.count > 0 {
+		w.buf.WriteString(",")
+	}
+	w.buf.WriteString("\n")
+	_, w.err = w.buf.Write(data)
+	w.count++
+}
+
+// WriteResult is a no-op: the archive is fed by the fetchers through Record.
+func (w *HARWriter) WriteResult(result *plugin.CrawlResult) error {
+	return nil
+}
+
+// Finalize closes the entries array and the file.
+func (w *HARWriter) Finalize(summary *plugin.CrawlSummary) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return w.err
+	}
+	w.buf.WriteString("\n]}}\n")
+	if err := w.buf.Flush(); err != nil && w.err == nil {
+		w.err = err
+	}
+	if err := w.file.Close(); err != nil && w.err == nil {
+		w.err = err
+	}
+	w.file = nil
+	return w.err
+}
+
+// ---------- HAR 1.2 types ----------
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+	ResourceType    string      `json:"_resourceType,omitempty"`
+	Fetcher         string      `json:"_fetcher,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// entry converts an Exchange into a HAR entry.
+func (w *HARWriter) entry(ex *fetcher.Exchange) harEntry {
+	protocol := harProtocol(ex.Protocol)
+
+	req := harRequest{
+		Method:      ex.Method,
+		URL:         ex.URL,
+		HTTPVersion: protocol,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(ex.RequestHeaders),
+		QueryString: harQuery(ex.URL),
+		HeadersSize: -1,
+		BodySize:    len(ex.RequestBody),
+	}
+	if len(ex.RequestBody) > 0 {
+		req.PostData = &harPostData{
+			MimeType: ex.RequestHeaders.Get("Content-Type"),
+			Text:     string(ex.RequestBody),
+		}
+	}
+
+	bodySize := ex.BodySize
+	if bodySize < 0 {
+		bodySize = len(ex.ResponseBody)
+	}
+	resp := harResponse{
+		Status:      ex.StatusCode,
+		StatusText:  ex.StatusText,
+		HTTPVersion: protocol,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(ex.ResponseHeaders),
+		Content:     w.content(ex),
+		RedirectURL: ex.RedirectURL,
+		HeadersSize: -1,
+		BodySize:    bodySize,
+	}
+	if resp.RedirectURL == "" && ex.ResponseHeaders != nil {
+		resp.RedirectURL = ex.ResponseHeaders.Get("Location")
+	}
+
+	return harEntry{
+		StartedDateTime: ex.StartedAt.UTC().Format(time.RFC3339Nano),
+		Time:            ms(ex.Timings.Total),
+		Request:         req,
+		Response:        resp,
+		Timings: harTimings{
+			Blocked: ms(ex.Timings.Blocked),
+			DNS:     ms(ex.Timings.DNS),
+			Connect: ms(ex.Timings.Connect),
+			Send:    nonNegative(ms(ex.Timings.Send)),
+			Wait:    nonNegative(ms(ex.Timings.Wait)),
+			Receive: nonNegative(ms(ex.Timings.Receive)),
+			SSL:     ms(ex.Timings.SSL),
+		},
+		Comment:      ex.Error,
+		ResourceType: ex.ResourceType,
+		Fetcher:      ex.Fetcher,
+	}
+}
+
+// content encodes the response body, capped at maxBodySize. Text bodies are
+// stored verbatim; binary bodies are base64 encoded.
+func (w *HARWriter) content(ex *fetcher.Exchange) harContent {
+	c := harContent{
+		Size:     len(ex.ResponseBody),
+		MimeType: ex.MimeType,
+	}
+	body := ex.ResponseBody
+	if w.maxBodySize > 0 && len(body) > w.maxBodySize {
+		body = body[:w.maxBodySize]
+		c.Comment = fmt.Sprintf("body truncated to %d of %d bytes", w.maxBodySize, len(ex.ResponseBody))
+	}
+	if len(body) == 0 {
+		return c
+	}
+	if isTextMime(ex.MimeType) && utf8.Valid(body) {
+		c.Text = string(body)
+	} else {
+		c.Text = base64.StdEncoding.EncodeToString(body)
+		c.Encoding = "base64"
+	}
+	return c
+}
+
+// ---------- helpers ----------
+
+func harHeaders(h http.Header) []harNameValue {
+	out := []harNameValue{}
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			out = append(out, harNameValue{Name: k, Value: v})
+		}
+	}
+	return out
+}
+
+func harQuery(rawURL string) []harNameValue {
+	out := []harNameValue{}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return out
+	}
+	q := u.Query()
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range q[k] {
+			out = append(out, harNameValue{Name: k, Value: v})
+		}
+	}
+	return out
+}
+
+func harProtocol(p string) string {
+	switch strings.ToLower(p) {
+	case "", "http/1.1":
+		return "HTTP/1.1"
+	case "h2", "http/2", "http/2.0":
+		return "HTTP/2"
+	case "h3", "http/3":
+		return "HTTP/3"
+	default:
+		return strings.ToUpper(p)
+	}
+}
+
+func isTextMime(mime string) bool {
+	mime = strings.ToLower(mime)
+	return strings.HasPrefix(mime, "text/") ||
+		strings.Contains(mime, "json") ||
+		strings.Contains(mime, "javascript") ||
+		strings.Contains(mime, "xml") ||
+		strings.Contains(mime, "x-www-form-urlencoded")
+}
+
+// ms converts a duration to HAR milliseconds; negative means "not measured".
+func ms(d time.Duration) float64 {
+	if d < 0 {
+		return -1
+	}
+	return float64(d.Microseconds()) / 1000
+}
+
+func nonNegative(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
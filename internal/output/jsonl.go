@@ -0,0 +1,59 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// JSONLWriter writes one JSON-encoded CrawlResult per line. The format is
+// streamable and is what `gofang diff` compares between runs.
+type JSONLWriter struct {
+	file *os.File
+	buf  *bufio.Writer
+	enc  *json.Encoder
+	mu   sync.Mutex
+}
+
+// NewJSONLWriter creates (or truncates) the file at path.
+func NewJSONLWriter(path string) (*JSONLWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(f)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	return &JSONLWriter{file: f, buf: buf, enc: enc}, nil
+}
+
+func (w *JSONLWriter) Name() string { return "jsonl" }
+
+func (w *JSONLWriter) WriteResult(result *plugin.CrawlResult) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	return w.enc.Encode(result)
+}
+
+// Finalize flushes and closes the file.
+func (w *JSONLWriter) Finalize(summary *plugin.CrawlSummary) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.buf.Flush()
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	w.file = nil
+	return err
+}
@@ -3,10 +3,12 @@ package output
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/ramkansal/gofang/internal/extractor"
 	"github.com/ramkansal/gofang/pkg/plugin"
 )
 
@@ -41,6 +43,15 @@ func (w *TextWriter) WriteResult(result *plugin.CrawlResult) error {
 	for _, item := range items {
 		w.lines = append(w.lines, fmt.Sprintf("      +-- %s: %s", item.Type, item.Value))
 	}
+	if p.DuplicateOf != "" {
+		w.lines = append(w.lines, fmt.Sprintf("      +-- duplicate of: %s (%.0f%% similar)", p.DuplicateOf, p.Similarity*100))
+	}
+	if p.ScreenshotPath != "" {
+		w.lines = append(w.lines, fmt.Sprintf("      +-- screenshot: %s", p.ScreenshotPath))
+	}
+	if p.PDFPath != "" {
+		w.lines = append(w.lines, fmt.Sprintf("      +-- pdf: %s", p.PDFPath))
+	}
 
 	return nil
 }
@@ -80,7 +91,7 @@ func (w *TextWriter) Finalize(summary *plugin.CrawlSummary) error {
 	if len(summary.ItemsByType) > 0 {
 		b.WriteString("    Types:  ")
 		first := true
-		for _, t := range []string{"link", "form", "email", "phone", "social", "metadata", "asset", "api_endpoint"} {
+		for _, t := range extractor.ItemTypes {
 			if count, ok := summary.ItemsByType[t]; ok && count > 0 {
 				if !first {
 					b.WriteString(", ")
@@ -91,6 +102,57 @@ func (w *TextWriter) Finalize(summary *plugin.CrawlSummary) error {
 		}
 		b.WriteString("\n")
 	}
+
+	var broken []plugin.LinkStatus
+	for _, l := range summary.LinkChecks {
+		if l.Broken {
+			broken = append(broken, l)
+		}
+	}
+	if len(summary.LinkChecks) > 0 {
+		b.WriteString(fmt.Sprintf("    Links:  %d checked, %d broken\n", len(summary.LinkChecks), len(broken)))
+		for _, l := range broken {
+			status := fmt.Sprintf("%d", l.StatusCode)
+			if l.StatusCode == 0 {
+				status = "ERR"
+			}
+			b.WriteString(fmt.Sprintf("      [%s] %s", status, l.URL))
+			if l.Error != "" {
+				b.WriteString(" (" + l.Error + ")")
+			}
+			b.WriteString("\n")
+			for _, hop := range l.Redirects {
+				b.WriteString(fmt.Sprintf("        +-- redirect: %d %s\n", hop.StatusCode, hop.Location))
+			}
+			for _, src := range l.Sources {
+				b.WriteString(fmt.Sprintf("        +-- linked from: %s\n", src))
+			}
+		}
+	}
+
+	if len(summary.DuplicateGroups) > 0 {
+		b.WriteString(fmt.Sprintf("    Dupes:  %d groups\n", len(summary.DuplicateGroups)))
+		for _, group := range summary.DuplicateGroups {
+			b.WriteString(fmt.Sprintf("      %s\n", group[0]))
+			for _, dup := range group[1:] {
+				b.WriteString(fmt.Sprintf("        = %s\n", dup))
+			}
+		}
+	}
+	for _, h := range summary.Security {
+		b.WriteString(fmt.Sprintf("    Security: %s, %d pages, %d findings %s\n",
+			h.Host, h.Pages, h.Findings, severityCounts(h.BySeverity)))
+		for _, issue := range sortedIssues(h.Issues) {
+			b.WriteString(fmt.Sprintf("      +-- %s (%d pages)\n", issue, h.Issues[issue]))
+		}
+	}
+	if a := summary.Accessibility; a != nil {
+		b.WriteString(fmt.Sprintf("    A11y:   %d pages, %d findings\n", a.Pages, a.Findings))
+		for _, issue := range sortedIssues(a.PagesAffected) {
+			b.WriteString(fmt.Sprintf("      +-- %s [WCAG %s]: %d findings (%d pages)\n",
+				issue, a.WCAG[issue], a.Issues[issue], a.PagesAffected[issue]))
+		}
+	}
 	b.WriteString("\n")
 
 	return os.WriteFile(w.path, []byte(b.String()), 0644)
@@ -98,6 +160,35 @@ func (w *TextWriter) Finalize(summary *plugin.CrawlSummary) error {
 
 // ---------- helpers ----------
 
+// severityCounts formats findings per severity, most severe first.
+func severityCounts(bySeverity map[string]int) string {
+	var parts []string
+	for _, sev := range []string{"high", "medium", "low", "info"} {
+		if n := bySeverity[sev]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s:%d", sev, n))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+// sortedIssues orders issues by the number of pages affected.
+func sortedIssues(issues map[string]int) []string {
+	keys := make([]string, 0, len(issues))
+	for k := range issues {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if issues[keys[i]] != issues[keys[j]] {
+			return issues[keys[i]] > issues[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
 func plainItemCounts(items []plugin.ExtractedItem) string {
 	if len(items) == 0 {
 		return ""
@@ -107,7 +198,7 @@ func plainItemCounts(items []plugin.ExtractedItem) string {
 		counts[item.Type]++
 	}
 	var parts []string
-	for _, t := range []string{"link", "form", "email", "phone", "social", "metadata", "asset", "api_endpoint"} {
+	for _, t := range extractor.ItemTypes {
 		if c, ok := counts[t]; ok && c > 0 {
 			short := t
 			if t == "api_endpoint" {
@@ -116,6 +207,9 @@ func plainItemCounts(items []plugin.ExtractedItem) string {
 			if t == "metadata" {
 				short = "meta"
 			}
+			if t == "structured_data" {
+				short = "schema"
+			}
 			parts = append(parts, fmt.Sprintf("%s:%d", short, c))
 		}
 	}
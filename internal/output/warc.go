@@ -0,0 +1,293 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// WARCWriter archives every crawled page as WARC 1.1 request, response and
+// metadata records; pages rendered by the browser become a resource record
+// holding the DOM instead, as their HTTP response isn't available. Each
+// record is a separate gzip member, and files are rotated once they exceed
+// maxSize bytes.
+type WARCWriter struct {
+	prefix  string
+	maxSize int64
+
+	file  *os.File
+	size  int64
+	index int
+	mu    sync.Mutex
+}
+
+// NewWARCWriter creates a writer whose files are named <prefix>-00000.warc.gz,
+// <prefix>-00001.warc.gz, ... A trailing .warc or .warc.gz on path is
+// dropped to form the prefix. A maxSize of 0 disables rotation.
+func NewWARCWriter(path string, maxSize int64) (*WARCWriter, error) {
+	prefix := strings.TrimSuffix(strings.TrimSuffix(path, ".gz"), ".warc")
+	w := &WARCWriter{prefix: prefix, maxSize: maxSize}
+	if err := w.rotate(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *WARCWriter) Name() string { return "warc" }
+
+// WriteResult appends the records for a page.
+func (w *WARCWriter) WriteResult(result *plugin.CrawlResult) error {
+	p := result.Page
+	if p == nil || p.StatusCode == 0 {
+		return nil
+	}
+
+	target := p.FinalURL
+	if target == "" {
+		target = p.URL
+	}
+	date := p.FetchedAt.UTC().Format(time.RFC3339)
+
+	type record struct {
+		headers [][2]string
+		block   []byte
+	}
+	var records []record
+	mainID := newRecordID()
+
+	if p.FetcherUsed == "browser" {
+		// The browser doesn't expose the server's response, only the
+		// rendered DOM, so archive that as a resource rather than
+		// inventing a status line and headers for it
+		dom := p.RenderedHTML
+		if dom == "" {
+			dom = p.RawHTML
+		}
+		records = append(records, record{
+			headers: [][2]string{
+				{"WARC-Type", "resource"},
+				{"WARC-Record-ID", mainID},
+				{"WARC-Date", date},
+				{"WARC-Target-URI", target},
+				{"Content-Type", "text/html; charset=utf-8"},
+			},
+			block: []byte(dom),
+		})
+	} else {
+		payload := []byte(p.RawHTML)
+		records = append(records,
+			record{
+				headers: [][2]string{
+					{"WARC-Type", "response"},
+					{"WARC-Record-ID", mainID},
+					{"WARC-Date", date},
+					{"WARC-Target-URI", target},
+					{"Content-Type", "application/http;msgtype=response"},
+					{"WARC-Payload-Digest", sha1Digest(payload)},
+				},
+				block: warcHTTPResponse(p, payload),
+			},
+			record{
+				headers: [][2]string{
+					{"WARC-Type", "request"},
+					{"WARC-Record-ID", newRecordID()},
+					{"WARC-Date", date},
+					{"WARC-Target-URI", target},
+					{"WARC-Concurrent-To", mainID},
+					{"Content-Type", "application/http;msgtype=request"},
+				},
+				block: warcHTTPRequest(target),
+			},
+		)
+	}
+	records = append(records, record{
+		headers: [][2]string{
+			{"WARC-Type", "metadata"},
+			{"WARC-Record-ID", newRecordID()},
+			{"WARC-Date", date},
+			{"WARC-Target-URI", target},
+			{"WARC-Refers-To", mainID},
+			{"Content-Type", "application/warc-fields"},
+		},
+		block: warcMetadata(result),
+	})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxSize > 0 && w.size >= w.maxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range records {
+		if err := w.writeRecord(r.headers, r.block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Finalize closes the current file.
+func (w *WARCWriter) Finalize(summary *plugin.CrawlSummary) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// rotate closes the current file (if any) and starts the next one with a
+// warcinfo record.
+func (w *WARCWriter) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+	}
+
+	name := fmt.Sprintf("%s-%05d.warc.gz", w.prefix, w.index)
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	w.file = f
+	w.size = 0
+	w.index++
+
+	info := []byte("software: gofang/1.0.0\r\nformat: WARC File Format 1.1\r\n" +
+		"conformsTo: https://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/\r\n")
+	return w.writeRecord([][2]string{
+		{"WARC-Type", "warcinfo"},
+		{"WARC-Record-ID", newRecordID()},
+		{"WARC-Date", time.Now().UTC().Format(time.RFC3339)},
+		{"WARC-Filename", filepath.Base(name)},
+		{"Content-Type", "application/warc-fields"},
+	}, info)
+}
+
+// writeRecord writes one record as its own gzip member.
+func (w *WARCWriter) writeRecord(headers [][2]string, block []byte) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+
+	fmt.Fprint(gz, "WARC/1.1\r\n")
+	for _, h := range headers {
+		fmt.Fprintf(gz, "%s: %s\r\n", h[0], h[1])
+	}
+	fmt.Fprintf(gz, "WARC-Block-Digest: %s\r\n", sha1Digest(block))
+	fmt.Fprintf(gz, "Content-Length: %d\r\n\r\n", len(block))
+	gz.Write(block)
+	fmt.Fprint(gz, "\r\n\r\n")
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	n, err := w.file.Write(buf.Bytes())
+	w.size += int64(n)
+	return err
+}
+
+// ---------- record blocks ----------
+
+// warcHTTPResponse rebuilds the HTTP response message from PageData. Bodies
+// are stored decoded, so transfer and content encodings are renamed to keep
+// the record replayable.
+func warcHTTPResponse(p *plugin.PageData, payload []byte) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "HTTP/1.1 %d %s\r\n", p.StatusCode, http.StatusText(p.StatusCode))
+
+	headers := p.Headers.Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	if headers.Get("Content-Type") == "" && p.ContentType != "" {
+		headers.Set("Content-Type", p.ContentType)
+	}
+	for _, h := range []string{"Content-Encoding", "Transfer-Encoding"} {
+		if v := headers.Values(h); len(v) > 0 {
+			headers["X-Crawler-"+h] = v
+			headers.Del(h)
+		}
+	}
+	headers.Set("Content-Length", fmt.Sprintf("%d", len(payload)))
+
+	writeHeaders(&b, headers)
+	b.WriteString("\r\n")
+	b.Write(payload)
+	return b.Bytes()
+}
+
+// warcHTTPRequest rebuilds the GET request that produced the response.
+func warcHTTPRequest(target string) []byte {
+	path, host := "/", ""
+	if u, err := url.Parse(target); err == nil {
+		path = u.RequestURI()
+		host = u.Host
+	}
+	return []byte(fmt.Sprintf("GET %s HTTP/1.1\r\nHost: %s\r\n\r\n", path, host))
+}
+
+// warcMetadata describes how the page was fetched and what it links to.
+func warcMetadata(result *plugin.CrawlResult) []byte {
+	p := result.Page
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "fetcher: %s\r\n", p.FetcherUsed)
+	fmt.Fprintf(&b, "depth: %d\r\n", p.Depth)
+	fmt.Fprintf(&b, "fetchTimeMs: %d\r\n", p.FetchDuration.Milliseconds())
+	if p.URL != p.FinalURL {
+		fmt.Fprintf(&b, "via: %s\r\n", p.URL)
+	}
+	for _, item := range result.ExtractedItems {
+		if item.Type == "link" {
+			fmt.Fprintf(&b, "outlink: %s\r\n", item.Value)
+		}
+	}
+	return b.Bytes()
+}
+
+// ---------- helpers ----------
+
+func writeHeaders(b *bytes.Buffer, h http.Header) {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			fmt.Fprintf(b, "%s: %s\r\n", k, v)
+		}
+	}
+}
+
+func sha1Digest(data []byte) string {
+	sum := sha1.Sum(data)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+func newRecordID() string {
+	var u [16]byte
+	_, _ = rand.Read(u[:])
+	u[6] = (u[6] & 0x0f) | 0x40 // version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
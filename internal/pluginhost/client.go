@@ -0,0 +1,291 @@
+package pluginhost
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// stderrTail is how much of a plugin's stderr is kept for crash reports.
+const stderrTail = 2048
+
+// exitWait bounds how long a killed plugin's output may stay open, for
+// when something other than the plugin still holds its pipes.
+const exitWait = 2 * time.Second
+
+type request struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id,omitempty"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type response struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// RPCError is an error reported by the plugin itself. The plugin stays
+// running after one.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("plugin error %d: %s", e.Code, e.Message)
+}
+
+// Client owns one plugin process. Calls are serialized, so a plugin only
+// ever handles one request at a time. The process is started on the
+// first call and restarted on the next call after a crash or timeout,
+// up to the spec's restart budget.
+type Client struct {
+	spec Spec
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	lines   chan []byte
+	exited  chan struct{}
+	stderr  *tailBuffer
+	nextID  int64
+	crashes int
+	closed  bool
+}
+
+// NewClient returns a client for spec. Nothing is started until the
+// first call.
+func NewClient(spec Spec) *Client {
+	return &Client{spec: spec}
+}
+
+// Name returns the plugin's declared name.
+func (c *Client) Name() string { return c.spec.Name }
+
+// Start launches the plugin and performs the handshake, if that hasn't
+// happened yet.
+func (c *Client) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ensureStarted()
+}
+
+// Call sends method with params and decodes the result into result,
+// which may be nil. It fails if the plugin crashes, answers with an
+// error or takes longer than the spec's timeout; in the last case the
+// process is killed so a late answer can't be mistaken for the next one.
+func (c *Client) Call(method string, params, result any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.ensureStarted(); err != nil {
+		return err
+	}
+	return c.roundTrip(method, params, result)
+}
+
+// ensureStarted starts the process if none is running, within the
+// restart budget, and sends it "initialize" so a restarted plugin sees
+// the same handshake as the first one.
+func (c *Client) ensureStarted() error {
+	if c.closed {
+		return fmt.Errorf("plugin %s is closed", c.spec.Name)
+	}
+	if c.cmd != nil {
+		return nil
+	}
+	if c.crashes > c.spec.restarts() {
+		return fmt.Errorf("plugin %s disabled after %d failures", c.spec.Name, c.crashes)
+	}
+	if err := c.start(); err != nil {
+		c.crashes++
+		return err
+	}
+	var hs Handshake
+	params := map[string]string{"name": c.spec.Name, "kind": c.spec.Kind}
+	if err := c.roundTrip("initialize", params, &hs); err != nil {
+		return err
+	}
+	if hs.Kind != "" && hs.Kind != c.spec.Kind {
+		return c.fail(fmt.Errorf("plugin reports kind %q, configured as %q", hs.Kind, c.spec.Kind))
+	}
+	return nil
+}
+
+func (c *Client) roundTrip(method string, params, result any) error {
+	c.nextID++
+	id := c.nextID
+	msg, err := json.Marshal(request{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("encode %s request: %w", method, err)
+	}
+	if _, err := c.stdin.Write(append(msg, '\n')); err != nil {
+		return c.fail(fmt.Errorf("write to plugin: %w", err))
+	}
+
+	timer := time.NewTimer(c.spec.Timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case line, ok := <-c.lines:
+			if !ok {
+				return c.fail(fmt.Errorf("plugin exited during %s", method))
+			}
+			var resp response
+			if err := json.Unmarshal(line, &resp); err != nil {
+				return c.fail(fmt.Errorf("invalid response to %s: %v", method, err))
+			}
+			if resp.ID != id {
+				// Notifications and stray answers are not ours
+				continue
+			}
+			if resp.Error != nil {
+				return resp.Error
+			}
+			if result == nil || len(resp.Result) == 0 || string(resp.Result) == "null" {
+				return nil
+			}
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("decode %s result: %w", method, err)
+			}
+			return nil
+		case <-timer.C:
+			return c.fail(fmt.Errorf("%s timed out after %s", method, c.spec.Timeout))
+		}
+	}
+}
+
+// Close asks the plugin to shut down, closes its stdin and kills it if
+// it hasn't exited within the call timeout. It is safe to call twice.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if c.cmd == nil {
+		return nil
+	}
+	if msg, err := json.Marshal(request{JSONRPC: "2.0", Method: "shutdown"}); err == nil {
+		_, _ = c.stdin.Write(append(msg, '\n'))
+	}
+	_ = c.stdin.Close()
+	go drain(c.lines)
+	select {
+	case <-c.exited:
+	case <-time.After(c.spec.Timeout):
+		c.kill()
+	}
+	c.cmd = nil
+	return nil
+}
+
+func (c *Client) start() error {
+	cmd := exec.Command(c.spec.Command[0], c.spec.Command[1:]...)
+	cmd.Dir = c.spec.Dir
+	cmd.Env = os.Environ()
+	for k, v := range c.spec.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("start plugin %s: %w", c.spec.Name, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("start plugin %s: %w", c.spec.Name, err)
+	}
+	c.stderr = &tailBuffer{}
+	cmd.Stderr = c.stderr
+	cmd.WaitDelay = exitWait
+	newProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start plugin %s: %w", c.spec.Name, err)
+	}
+
+	lines := make(chan []byte)
+	exited := make(chan struct{})
+	go func() {
+		r := bufio.NewReader(stdout)
+		for {
+			line, err := r.ReadBytes('\n')
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				lines <- line
+			}
+			if err != nil {
+				break
+			}
+		}
+		close(lines)
+		_ = cmd.Wait()
+		close(exited)
+	}()
+
+	c.cmd, c.stdin, c.lines, c.exited = cmd, stdin, lines, exited
+	return nil
+}
+
+// fail kills the process after a crash or timeout so the next call
+// starts a fresh one, and wraps err with whatever the plugin last wrote
+// to stderr.
+func (c *Client) fail(err error) error {
+	c.crashes++
+	_ = c.stdin.Close()
+	go drain(c.lines)
+	c.kill()
+	c.cmd = nil
+
+	err = fmt.Errorf("plugin %s: %w", c.spec.Name, err)
+	if tail := c.stderr.String(); tail != "" {
+		err = fmt.Errorf("%w (stderr: %s)", err, tail)
+	}
+	return err
+}
+
+// kill kills the plugin with any processes it started and waits for its
+// output to close, giving up after exitWait so a process that escaped
+// the kill can't block the caller.
+func (c *Client) kill() {
+	killProcessGroup(c.cmd)
+	select {
+	case <-c.exited:
+	case <-time.After(exitWait):
+	}
+}
+
+// drain discards a dead process's remaining output so its reader
+// goroutine can reach Wait.
+func drain(lines <-chan []byte) {
+	for range lines {
+	}
+}
+
+// tailBuffer keeps the last stderrTail bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - stderrTail; over > 0 {
+		t.buf = t.buf[over:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(bytes.TrimSpace(t.buf))
+}
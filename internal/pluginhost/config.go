@@ -0,0 +1,103 @@
+// Package pluginhost runs extractors, fetchers and output writers as
+// external processes. Each plugin is an executable that speaks JSON-RPC
+// 2.0 over its stdin and stdout, one message per line, so plugins can be
+// written in any language. A plugin that crashes or stops answering is
+// killed and restarted without taking the crawl down with it.
+package pluginhost
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Plugin kinds, matching the interfaces in pkg/plugin.
+const (
+	KindExtractor = "extractor"
+	KindFetcher   = "fetcher"
+	KindOutput    = "output"
+)
+
+// Default limits for a plugin that doesn't set its own.
+const (
+	DefaultTimeout  = 10 * time.Second
+	DefaultRestarts = 3
+)
+
+// Spec declares one external plugin.
+type Spec struct {
+	Name     string            `yaml:"name"`
+	Kind     string            `yaml:"kind"`     // extractor, fetcher or output
+	Command  []string          `yaml:"command"`  // executable and arguments
+	Env      map[string]string `yaml:"env"`      // added to the crawler's environment
+	Dir      string            `yaml:"dir"`      // working directory
+	Timeout  time.Duration     `yaml:"timeout"`  // per call
+	Restarts int               `yaml:"restarts"` // restarts after a crash; -1 never restarts
+}
+
+func (s *Spec) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("plugin without a name")
+	}
+	switch s.Kind {
+	case KindExtractor, KindFetcher, KindOutput:
+	default:
+		return fmt.Errorf("plugin %s: unknown kind %q (want extractor, fetcher or output)", s.Name, s.Kind)
+	}
+	if len(s.Command) == 0 {
+		return fmt.Errorf("plugin %s: no command", s.Name)
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = DefaultTimeout
+	}
+	return nil
+}
+
+// restarts returns how many crashes the plugin may be restarted after.
+// Restarts is left as configured so validating a spec twice is harmless.
+func (s *Spec) restarts() int {
+	switch {
+	case s.Restarts < 0:
+		return 0
+	case s.Restarts == 0:
+		return DefaultRestarts
+	}
+	return s.Restarts
+}
+
+// LoadConfig reads plugin declarations from a YAML file with a top-level
+// "plugins" list. Names must be unique and at most one fetcher may be
+// declared.
+func LoadConfig(path string) ([]Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	var file struct {
+		Plugins []Spec `yaml:"plugins"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	fetchers := 0
+	for i := range file.Plugins {
+		spec := &file.Plugins[i]
+		if err := spec.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if seen[spec.Name] {
+			return nil, fmt.Errorf("%s: duplicate plugin %q", path, spec.Name)
+		}
+		seen[spec.Name] = true
+		if spec.Kind == KindFetcher {
+			if fetchers++; fetchers > 1 {
+				return nil, fmt.Errorf("%s: only one fetcher plugin may be declared", path)
+			}
+		}
+	}
+	return file.Plugins, nil
+}
@@ -0,0 +1,141 @@
+package pluginhost
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// wirePage is PageData as sent to and received from plugins. Unlike the
+// crawl output it carries the body and headers.
+type wirePage struct {
+	*plugin.PageData
+	Headers      http.Header `json:"headers,omitempty"`
+	RawHTML      string      `json:"raw_html,omitempty"`
+	RenderedHTML string      `json:"rendered_html,omitempty"`
+}
+
+func toWire(page *plugin.PageData) *wirePage {
+	if page == nil {
+		return nil
+	}
+	return &wirePage{
+		PageData:     page,
+		Headers:      page.Headers,
+		RawHTML:      page.RawHTML,
+		RenderedHTML: page.RenderedHTML,
+	}
+}
+
+type wireResult struct {
+	Page           *wirePage              `json:"page"`
+	ExtractedItems []plugin.ExtractedItem `json:"extracted_items"`
+}
+
+// Handshake is the plugin's answer to "initialize". A plugin that
+// reports a kind must report the one it was configured as.
+type Handshake struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// Open starts the plugin declared by spec and returns it as the
+// matching pkg/plugin interface: a plugin.Extractor, plugin.Fetcher or
+// plugin.OutputWriter.
+func Open(spec Spec) (any, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	c := NewClient(spec)
+	if err := c.Start(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	switch spec.Kind {
+	case KindExtractor:
+		return &Extractor{c}, nil
+	case KindFetcher:
+		return &Fetcher{c}, nil
+	default:
+		return &OutputWriter{c}, nil
+	}
+}
+
+// Extractor is a plugin.Extractor backed by a plugin process. The
+// "extract" method receives {"page": ...} and returns {"items": [...]}.
+type Extractor struct{ *Client }
+
+func (e *Extractor) Extract(page *plugin.PageData) ([]plugin.ExtractedItem, error) {
+	var res struct {
+		Items []plugin.ExtractedItem `json:"items"`
+	}
+	if err := e.Call("extract", map[string]any{"page": toWire(page)}, &res); err != nil {
+		return nil, err
+	}
+	for i := range res.Items {
+		if res.Items[i].SourceURL == "" {
+			res.Items[i].SourceURL = page.URL
+		}
+	}
+	return res.Items, nil
+}
+
+// Fetcher is a plugin.Fetcher backed by a plugin process. The "fetch"
+// method receives {"url": ..., "depth": ...} and returns a page.
+type Fetcher struct{ *Client }
+
+func (f *Fetcher) Fetch(url string, depth int) (*plugin.PageData, error) {
+	start := time.Now()
+	res := wirePage{PageData: &plugin.PageData{}}
+	if err := f.Call("fetch", map[string]any{"url": url, "depth": depth}, &res); err != nil {
+		return nil, err
+	}
+	page := res.PageData
+	page.Headers = res.Headers
+	page.RawHTML = res.RawHTML
+	page.RenderedHTML = res.RenderedHTML
+	if page.URL == "" {
+		page.URL = url
+	}
+	if page.FinalURL == "" {
+		page.FinalURL = page.URL
+	}
+	if page.FetcherUsed == "" {
+		page.FetcherUsed = f.Name()
+	}
+	page.Depth = depth
+	if page.FetchedAt.IsZero() {
+		page.FetchedAt = start
+	}
+	if page.FetchDuration == 0 {
+		page.FetchDuration = time.Since(start)
+	}
+	if page.ResponseSize == 0 {
+		page.ResponseSize = len(page.RawHTML)
+	}
+	if page.Error != "" {
+		return page, fmt.Errorf("%s", page.Error)
+	}
+	return page, nil
+}
+
+// OutputWriter is a plugin.OutputWriter backed by a plugin process. It
+// receives "write_result" with {"result": ...} per page and "finalize"
+// with {"summary": ...} at the end, after which the process is shut
+// down.
+type OutputWriter struct{ *Client }
+
+func (w *OutputWriter) WriteResult(result *plugin.CrawlResult) error {
+	return w.Call("write_result", map[string]any{"result": wireResult{
+		Page:           toWire(result.Page),
+		ExtractedItems: result.ExtractedItems,
+	}}, nil)
+}
+
+func (w *OutputWriter) Finalize(summary *plugin.CrawlSummary) error {
+	err := w.Call("finalize", map[string]any{"summary": summary}, nil)
+	w.Close()
+	return err
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package pluginhost
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// newProcessGroup starts the plugin in its own process group, so a plugin
+// launched through a wrapper (sh -c, npx, ...) can be killed with
+// everything it started.
+func newProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the plugin's process group.
+func killProcessGroup(cmd *exec.Cmd) {
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		_ = cmd.Process.Kill()
+	}
+}
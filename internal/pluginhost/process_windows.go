@@ -0,0 +1,14 @@
+//go:build windows
+
+package pluginhost
+
+import "os/exec"
+
+func newProcessGroup(cmd *exec.Cmd) {
+	// Windows has no process groups to kill; the bounded waits in Client
+	// keep a surviving child from blocking the crawl.
+}
+
+func killProcessGroup(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
@@ -0,0 +1,470 @@
+// Package seo evaluates the metadata collected during a crawl and reports
+// site-wide SEO problems: missing or duplicate titles and descriptions, H1
+// issues, canonical mismatches, noindex pages that are linked internally,
+// inconsistent hreflang annotations and thin content.
+package seo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ramkansal/gofang/internal/dedup"
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// DefaultThinWords is the visible word count below which a page counts as
+// thin content.
+const DefaultThinWords = 200
+
+// hreflangPattern accepts a language, optional script and optional region
+// (e.g. "en", "en-GB", "zh-Hant-TW", "es-419") or "x-default".
+var hreflangPattern = regexp.MustCompile(`(?i)^([a-z]{2,3}(-[a-z]{4})?(-([a-z]{2}|[0-9]{3}))?|x-default)$`)
+
+// page is what the auditor keeps about each analysed page.
+type page struct {
+	url          string
+	titles       []string
+	descriptions []string
+	h1           int
+	canonicals   []string
+	noindex      bool
+	hreflang     [][2]string // lang, target URL
+	words        int
+	links        []string // internal link targets
+}
+
+// Auditor collects pages as the crawl progresses. It is safe for
+// concurrent use.
+type Auditor struct {
+	// ThinWords is the thin-content threshold; 0 disables the check.
+	ThinWords int
+
+	normalize func(string) string
+
+	mu     sync.Mutex
+	pages  map[string]*page
+	status map[string]int // every fetched URL -> status, analysed or not
+}
+
+// New creates an Auditor. normalize maps link, canonical and hreflang URLs
+// onto the form the crawler uses for page URLs; nil keeps them as they are.
+func New(normalize func(string) string) *Auditor {
+	if normalize == nil {
+		normalize = func(s string) string { return s }
+	}
+	return &Auditor{
+		ThinWords: DefaultThinWords,
+		normalize: normalize,
+		pages:     make(map[string]*page),
+		status:    make(map[string]int),
+	}
+}
+
+// Add records a crawl result. Only successful HTML pages are analysed.
+func (a *Auditor) Add(result *plugin.CrawlResult) {
+	if result == nil || result.Page == nil {
+		return
+	}
+	pd := result.Page
+
+	a.mu.Lock()
+	a.status[pd.URL] = pd.StatusCode
+	a.mu.Unlock()
+
+	if pd.StatusCode < 200 || pd.StatusCode >= 300 || pd.RawHTML == "" {
+		return
+	}
+	if pd.ContentType != "" && !strings.Contains(strings.ToLower(pd.ContentType), "html") {
+		return
+	}
+
+	base, _ := url.Parse(pd.FinalURL)
+	if base == nil || pd.FinalURL == "" {
+		base, _ = url.Parse(pd.URL)
+	}
+	resolve := func(raw string) string {
+		ref, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil || base == nil {
+			return raw
+		}
+		return a.normalize(base.ResolveReference(ref).String())
+	}
+
+	p := &page{url: pd.URL}
+	for _, v := range pd.Headers.Values("X-Robots-Tag") {
+		if strings.Contains(strings.ToLower(v), "noindex") {
+			p.noindex = true
+		}
+	}
+	for _, item := range result.ExtractedItems {
+		switch item.Type {
+		case "link":
+			if item.Metadata["link_type"] == "internal" {
+				p.links = append(p.links, a.normalize(item.Value))
+			}
+		case "metadata":
+			value := strings.Join(strings.Fields(item.Value), " ")
+			switch item.Metadata["field"] {
+			case "title":
+				p.titles = append(p.titles, value)
+			case "description":
+				p.descriptions = append(p.descriptions, value)
+			case "h1":
+				p.h1++
+			case "canonical":
+				p.canonicals = append(p.canonicals, resolve(item.Value))
+			case "robots", "googlebot":
+				if strings.Contains(strings.ToLower(value), "noindex") {
+					p.noindex = true
+				}
+			case "hreflang":
+				p.hreflang = append(p.hreflang, [2]string{item.Metadata["hreflang"], resolve(item.Value)})
+			}
+		}
+	}
+	p.words = len(strings.Fields(dedup.VisibleText(pd.RawHTML)))
+
+	a.mu.Lock()
+	a.pages[pd.URL] = p
+	a.mu.Unlock()
+}
+
+// ---------- Report ----------
+
+// Report is the site-wide SEO analysis. Every list is sorted by URL.
+type Report struct {
+	Pages                 int              `json:"pages_analyzed"`
+	MissingTitle          []string         `json:"missing_title"`
+	DuplicateTitles       []Duplicate      `json:"duplicate_titles"`
+	MissingDescription    []string         `json:"missing_description"`
+	DuplicateDescriptions []Duplicate      `json:"duplicate_descriptions"`
+	MissingH1             []string         `json:"missing_h1"`
+	MultipleH1            []PageCount      `json:"multiple_h1"`
+	Canonical             []CanonicalIssue `json:"canonical"`
+	NoindexLinked         []NoindexLinked  `json:"noindex_linked"`
+	Hreflang              []HreflangIssue  `json:"hreflang"`
+	ThinContent           []PageCount      `json:"thin_content"`
+}
+
+// Duplicate is a title or description shared by several pages.
+type Duplicate struct {
+	Value string   `json:"value"`
+	URLs  []string `json:"urls"`
+}
+
+// PageCount pairs a page with a count (H1 elements, words).
+type PageCount struct {
+	URL   string `json:"url"`
+	Count int    `json:"count"`
+}
+
+// CanonicalIssue is a page whose canonical URL needs attention.
+type CanonicalIssue struct {
+	URL       string `json:"url"`
+	Canonical string `json:"canonical"`
+	Problem   string `json:"problem"`
+}
+
+// NoindexLinked is a noindex page together with the pages linking to it.
+type NoindexLinked struct {
+	URL        string   `json:"url"`
+	LinkedFrom []string `json:"linked_from"`
+}
+
+// HreflangIssue is an inconsistent or invalid hreflang annotation.
+type HreflangIssue struct {
+	URL     string `json:"url"`
+	Lang    string `json:"lang"`
+	Target  string `json:"target,omitempty"`
+	Problem string `json:"problem"`
+}
+
+// Issues returns the total number of problems found.
+func (r *Report) Issues() int {
+	total := 0
+	for _, c := range r.counts() {
+		total += c.n
+	}
+	return total
+}
+
+// Report analyses every page added so far.
+func (a *Auditor) Report() *Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	urls := make([]string, 0, len(a.pages))
+	for u := range a.pages {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	r := &Report{Pages: len(urls)}
+	titles := make(map[string][]string)
+	descriptions := make(map[string][]string)
+
+	for _, u := range urls {
+		p := a.pages[u]
+
+		if len(p.titles) == 0 || p.titles[0] == "" {
+			r.MissingTitle = append(r.MissingTitle, u)
+		} else {
+			titles[p.titles[0]] = append(titles[p.titles[0]], u)
+		}
+		if len(p.descriptions) == 0 || p.descriptions[0] == "" {
+			r.MissingDescription = append(r.MissingDescription, u)
+		} else {
+			descriptions[p.descriptions[0]] = append(descriptions[p.descriptions[0]], u)
+		}
+
+		switch {
+		case p.h1 == 0:
+			r.MissingH1 = append(r.MissingH1, u)
+		case p.h1 > 1:
+			r.MultipleH1 = append(r.MultipleH1, PageCount{URL: u, Count: p.h1})
+		}
+
+		if a.ThinWords > 0 && p.words < a.ThinWords {
+			r.ThinContent = append(r.ThinContent, PageCount{URL: u, Count: p.words})
+		}
+
+		r.Canonical = append(r.Canonical, a.canonicalIssues(p)...)
+		r.Hreflang = append(r.Hreflang, a.hreflangIssues(p)...)
+	}
+
+	r.DuplicateTitles = duplicates(titles)
+	r.DuplicateDescriptions = duplicates(descriptions)
+	r.NoindexLinked = a.noindexLinked(urls)
+	return r
+}
+
+func (a *Auditor) canonicalIssues(p *page) []CanonicalIssue {
+	if len(p.canonicals) == 0 {
+		return nil
+	}
+	var issues []CanonicalIssue
+	distinct := make(map[string]bool)
+	for _, c := range p.canonicals {
+		distinct[c] = true
+	}
+	if len(distinct) > 1 {
+		issues = append(issues, CanonicalIssue{URL: p.url, Canonical: strings.Join(p.canonicals, " "), Problem: "conflicting canonical tags"})
+	}
+
+	canonical := p.canonicals[0]
+	if canonical == a.normalize(p.url) {
+		return issues
+	}
+	problem := "canonical points to another URL"
+	if status, ok := a.status[canonical]; ok && (status < 200 || status >= 300) {
+		problem = "canonical target returned a non-2xx status"
+	} else if target, ok := a.pages[canonical]; ok && target.noindex {
+		problem = "canonical target is noindex"
+	}
+	return append(issues, CanonicalIssue{URL: p.url, Canonical: canonical, Problem: problem})
+}
+
+func (a *Auditor) hreflangIssues(p *page) []HreflangIssue {
+	if len(p.hreflang) == 0 {
+		return nil
+	}
+	var issues []HreflangIssue
+	self := a.normalize(p.url)
+	byLang := make(map[string]string)
+	selfRef := false
+
+	for _, h := range p.hreflang {
+		lang, target := h[0], h[1]
+		if !hreflangPattern.MatchString(lang) {
+			issues = append(issues, HreflangIssue{URL: p.url, Lang: lang, Target: target, Problem: "invalid language code"})
+		}
+		key := strings.ToLower(lang)
+		if prev, ok := byLang[key]; ok && prev != target {
+			issues = append(issues, HreflangIssue{URL: p.url, Lang: lang, Target: target, Problem: "language mapped to several URLs"})
+		}
+		byLang[key] = target
+
+		if target == self {
+			selfRef = true
+			continue
+		}
+		// Alternates must link back; only verifiable for crawled pages
+		if alt, ok := a.pages[target]; ok {
+			back := false
+			for _, ah := range alt.hreflang {
+				if ah[1] == self {
+					back = true
+					break
+				}
+			}
+			if !back {
+				issues = append(issues, HreflangIssue{URL: p.url, Lang: lang, Target: target, Problem: "no return link from alternate"})
+			}
+		}
+	}
+	if !selfRef {
+		issues = append(issues, HreflangIssue{URL: p.url, Problem: "no self-referencing hreflang"})
+	}
+	return issues
+}
+
+func (a *Auditor) noindexLinked(urls []string) []NoindexLinked {
+	noindex := make(map[string]string) // normalized -> page URL
+	for _, u := range urls {
+		if a.pages[u].noindex {
+			noindex[a.normalize(u)] = u
+		}
+	}
+	if len(noindex) == 0 {
+		return nil
+	}
+
+	sources := make(map[string][]string)
+	for _, u := range urls {
+		seen := make(map[string]bool)
+		for _, link := range a.pages[u].links {
+			target, ok := noindex[link]
+			if !ok || target == u || seen[target] {
+				continue
+			}
+			seen[target] = true
+			sources[target] = append(sources[target], u)
+		}
+	}
+
+	var out []NoindexLinked
+	for _, u := range urls {
+		if from := sources[u]; len(from) > 0 {
+			out = append(out, NoindexLinked{URL: u, LinkedFrom: from})
+		}
+	}
+	return out
+}
+
+// duplicates returns the values shared by more than one page, sorted by
+// value.
+func duplicates(byValue map[string][]string) []Duplicate {
+	var out []Duplicate
+	for v, urls := range byValue {
+		if len(urls) > 1 {
+			out = append(out, Duplicate{Value: v, URLs: urls})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Value < out[j].Value })
+	return out
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
+}
+
+// WriteSummary writes one count per check.
+func (r *Report) WriteSummary(w io.Writer) {
+	fmt.Fprintf(w, "  Pages analyzed: %d, issues: %d\n", r.Pages, r.Issues())
+	for _, c := range r.counts() {
+		if c.n > 0 {
+			fmt.Fprintf(w, "    %-24s %d\n", c.label+":", c.n)
+		}
+	}
+}
+
+// WriteText writes a human-readable report listing every affected page.
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "  Pages analyzed: %d, issues: %d\n", r.Pages, r.Issues())
+	if r.Issues() == 0 {
+		fmt.Fprintf(w, "  No issues\n")
+		return
+	}
+
+	section := func(label string, n int) bool {
+		if n == 0 {
+			return false
+		}
+		fmt.Fprintf(w, "  %s (%d):\n", label, n)
+		return true
+	}
+	pages := func(label string, urls []string) {
+		if section(label, len(urls)) {
+			for _, u := range urls {
+				fmt.Fprintf(w, "    %s\n", u)
+			}
+		}
+	}
+	dupes := func(label string, groups []Duplicate) {
+		if section(label, len(groups)) {
+			for _, g := range groups {
+				fmt.Fprintf(w, "    %q\n", g.Value)
+				for _, u := range g.URLs {
+					fmt.Fprintf(w, "      %s\n", u)
+				}
+			}
+		}
+	}
+	counts := func(label, unit string, list []PageCount) {
+		if section(label, len(list)) {
+			for _, pc := range list {
+				fmt.Fprintf(w, "    %s (%d %s)\n", pc.URL, pc.Count, unit)
+			}
+		}
+	}
+
+	pages("Missing title", r.MissingTitle)
+	dupes("Duplicate titles", r.DuplicateTitles)
+	pages("Missing description", r.MissingDescription)
+	dupes("Duplicate descriptions", r.DuplicateDescriptions)
+	pages("Missing H1", r.MissingH1)
+	counts("Multiple H1", "h1", r.MultipleH1)
+	if section("Canonical", len(r.Canonical)) {
+		for _, c := range r.Canonical {
+			fmt.Fprintf(w, "    %s -> %s (%s)\n", c.URL, c.Canonical, c.Problem)
+		}
+	}
+	if section("Noindex but linked internally", len(r.NoindexLinked)) {
+		for _, n := range r.NoindexLinked {
+			fmt.Fprintf(w, "    %s (linked from %d pages)\n", n.URL, len(n.LinkedFrom))
+			for _, from := range n.LinkedFrom {
+				fmt.Fprintf(w, "      <- %s\n", from)
+			}
+		}
+	}
+	if section("Hreflang", len(r.Hreflang)) {
+		for _, h := range r.Hreflang {
+			if h.Lang == "" {
+				fmt.Fprintf(w, "    %s (%s)\n", h.URL, h.Problem)
+				continue
+			}
+			fmt.Fprintf(w, "    %s [%s] -> %s (%s)\n", h.URL, h.Lang, h.Target, h.Problem)
+		}
+	}
+	counts("Thin content", "words", r.ThinContent)
+}
+
+type count struct {
+	label string
+	n     int
+}
+
+func (r *Report) counts() []count {
+	return []count{
+		{"Missing title", len(r.MissingTitle)},
+		{"Duplicate titles", len(r.DuplicateTitles)},
+		{"Missing description", len(r.MissingDescription)},
+		{"Duplicate descriptions", len(r.DuplicateDescriptions)},
+		{"Missing H1", len(r.MissingH1)},
+		{"Multiple H1", len(r.MultipleH1)},
+		{"Canonical", len(r.Canonical)},
+		{"Noindex linked", len(r.NoindexLinked)},
+		{"Hreflang", len(r.Hreflang)},
+		{"Thin content", len(r.ThinContent)},
+	}
+}
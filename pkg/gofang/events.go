@@ -0,0 +1,127 @@
+package gofang
+
+import (
+	"errors"
+
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// handlers holds the callbacks registered on a Crawler, per event type
+// and in registration order.
+type handlers struct {
+	event          []func(plugin.CrawlEvent)
+	crawlStarted   []func(target string)
+	crawlFinished  []func(stats *plugin.CrawlStats)
+	pageQueued     []func(url string)
+	pageStarted    []func(url string)
+	pageDone       []func(result *plugin.CrawlResult)
+	pageError      []func(url string, err error)
+	extractorError []func(url string, err error)
+	linkChecked    []func(link *plugin.LinkStatus)
+	progress       []func(message string)
+}
+
+// OnEvent is called for every event, before the typed callbacks.
+func (c *Crawler) OnEvent(fn func(plugin.CrawlEvent)) {
+	c.handlers.event = append(c.handlers.event, fn)
+}
+
+// OnCrawlStarted is called once with the target URL before the first
+// page is fetched.
+func (c *Crawler) OnCrawlStarted(fn func(target string)) {
+	c.handlers.crawlStarted = append(c.handlers.crawlStarted, fn)
+}
+
+// OnCrawlFinished is called once with the final stats after the output
+// writers are finalized.
+func (c *Crawler) OnCrawlFinished(fn func(stats *plugin.CrawlStats)) {
+	c.handlers.crawlFinished = append(c.handlers.crawlFinished, fn)
+}
+
+// OnPageQueued is called when a URL is added to the frontier.
+func (c *Crawler) OnPageQueued(fn func(url string)) {
+	c.handlers.pageQueued = append(c.handlers.pageQueued, fn)
+}
+
+// OnPageStarted is called when a URL is about to be fetched.
+func (c *Crawler) OnPageStarted(fn func(url string)) {
+	c.handlers.pageStarted = append(c.handlers.pageStarted, fn)
+}
+
+// OnPageDone is called with each page and its extracted items. The
+// result must not be modified.
+func (c *Crawler) OnPageDone(fn func(result *plugin.CrawlResult)) {
+	c.handlers.pageDone = append(c.handlers.pageDone, fn)
+}
+
+// OnPageError is called when a page can't be fetched, and for crawl-wide
+// problems such as an output that can't be written, where url is empty.
+func (c *Crawler) OnPageError(fn func(url string, err error)) {
+	c.handlers.pageError = append(c.handlers.pageError, fn)
+}
+
+// OnExtractorError is called when an extractor fails, panics or times
+// out on a page. err is an error wrapping the extractor's failure.
+func (c *Crawler) OnExtractorError(fn func(url string, err error)) {
+	c.handlers.extractorError = append(c.handlers.extractorError, fn)
+}
+
+// OnLinkChecked is called for every link verified by WithLinkCheck.
+func (c *Crawler) OnLinkChecked(fn func(link *plugin.LinkStatus)) {
+	c.handlers.linkChecked = append(c.handlers.linkChecked, fn)
+}
+
+// OnProgress is called with progress messages, such as the link
+// checker's.
+func (c *Crawler) OnProgress(fn func(message string)) {
+	c.handlers.progress = append(c.handlers.progress, fn)
+}
+
+// dispatch routes an engine event to the callbacks for its type.
+func (h *handlers) dispatch(e plugin.CrawlEvent) {
+	for _, fn := range h.event {
+		fn(e)
+	}
+	switch e.Type {
+	case plugin.EventCrawlStarted:
+		for _, fn := range h.crawlStarted {
+			fn(e.URL)
+		}
+	case plugin.EventCrawlFinished:
+		for _, fn := range h.crawlFinished {
+			fn(e.Stats)
+		}
+	case plugin.EventPageQueued:
+		for _, fn := range h.pageQueued {
+			fn(e.URL)
+		}
+	case plugin.EventPageStarted:
+		for _, fn := range h.pageStarted {
+			fn(e.URL)
+		}
+	case plugin.EventPageDone:
+		for _, fn := range h.pageDone {
+			fn(e.Result)
+		}
+	case plugin.EventPageError:
+		err := e.Error
+		if err == nil {
+			err = errors.New(e.Message)
+		}
+		for _, fn := range h.pageError {
+			fn(e.URL, err)
+		}
+	case plugin.EventExtractorError:
+		for _, fn := range h.extractorError {
+			fn(e.URL, e.Error)
+		}
+	case plugin.EventLinkChecked:
+		for _, fn := range h.linkChecked {
+			fn(e.Link)
+		}
+	case plugin.EventProgress:
+		for _, fn := range h.progress {
+			fn(e.Message)
+		}
+	}
+}
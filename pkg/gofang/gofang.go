@@ -0,0 +1,135 @@
+// Package gofang runs crawls from Go programs. It wraps the same engine
+// as the gofang command: configure a Crawler with options, register
+// custom extractors, output writers or a fetcher from pkg/plugin, attach
+// callbacks for the events you care about and call Run.
+//
+//	c, err := gofang.New("https://example.com",
+//		gofang.WithMaxDepth(2),
+//		gofang.WithExtractors("links", "emails"),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	c.OnPageDone(func(r *plugin.CrawlResult) {
+//		fmt.Println(r.Page.URL, len(r.ExtractedItems))
+//	})
+//	summary, err := c.Run(ctx)
+//
+// # Compatibility
+//
+// This package and pkg/plugin follow semantic versioning together with
+// the module. Within a major version, exported identifiers are not
+// removed or changed incompatibly; new options, callbacks, struct fields
+// and event types may be added in minor releases. Breaking changes only
+// ship under a new major version's import path. Packages under
+// internal/ carry no such guarantee and cannot be imported.
+package gofang
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/ramkansal/gofang/internal/crawler"
+	"github.com/ramkansal/gofang/pkg/plugin"
+)
+
+// ErrAlreadyRun is returned by Run on a Crawler that has run before.
+var ErrAlreadyRun = errors.New("gofang: crawler has already run")
+
+// Crawler is a single crawl. It is configured with options and
+// registrations before Run and cannot be run twice.
+type Crawler struct {
+	config     *crawler.CrawlConfig
+	extractors []plugin.Extractor
+	writers    []plugin.OutputWriter
+	fetcher    plugin.Fetcher
+	handlers   handlers
+	ran        bool
+}
+
+// New creates a crawler for target, an http or https URL, with the same
+// defaults as the command line tool.
+func New(target string, opts ...Option) (*Crawler, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("gofang: invalid target URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("gofang: target must be an absolute http or https URL, got %q", target)
+	}
+
+	c := &Crawler{config: crawler.DefaultConfig()}
+	c.config.TargetURL = target
+	o := &options{config: c.config}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, fmt.Errorf("gofang: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// RegisterExtractor adds an extractor that runs on every page after the
+// built-in ones. Its name must differ from every other extractor's, and
+// it must be safe for concurrent use.
+func (c *Crawler) RegisterExtractor(ext plugin.Extractor) {
+	c.extractors = append(c.extractors, ext)
+}
+
+// RegisterOutputWriter adds an output writer. WriteResult is called for
+// every page and Finalize once when the crawl ends.
+func (c *Crawler) RegisterOutputWriter(w plugin.OutputWriter) {
+	c.writers = append(c.writers, w)
+}
+
+// RegisterFetcher replaces the built-in HTTP fetcher. The browser
+// fetcher, when enabled with WithFetcher, is unaffected. The crawler
+// closes f when the crawl ends.
+func (c *Crawler) RegisterFetcher(f plugin.Fetcher) {
+	c.fetcher = f
+}
+
+// Run crawls until the frontier is exhausted, the page limit is reached
+// or ctx is done, and returns the crawl summary. When ctx ends
+// the crawl no new pages are started; pages in flight finish, writers
+// are finalized and the partial summary is returned with ctx's error.
+//
+// Callbacks are called from the crawl's goroutines, one at a time, so
+// they need no locking of their own but should return quickly.
+func (c *Crawler) Run(ctx context.Context) (*plugin.CrawlSummary, error) {
+	if c.ran {
+		return nil, ErrAlreadyRun
+	}
+	c.ran = true
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	engine := crawler.New(c.config)
+	for _, ext := range c.extractors {
+		engine.RegisterExtractor(ext)
+	}
+	for _, w := range c.writers {
+		engine.RegisterWriter(w)
+	}
+	if c.fetcher != nil {
+		engine.SetFetcher(c.fetcher)
+	}
+	engine.SetEventHandler(c.handlers.dispatch)
+
+	if err := engine.Init(); err != nil {
+		engine.Close()
+		return nil, fmt.Errorf("gofang: %w", err)
+	}
+	defer engine.Close()
+
+	stop := context.AfterFunc(ctx, engine.Stop)
+	defer stop()
+
+	if err := engine.Run(); err != nil {
+		return engine.Summary(), fmt.Errorf("gofang: %w", err)
+	}
+	return engine.Summary(), ctx.Err()
+}
@@ -0,0 +1,264 @@
+package gofang
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ramkansal/gofang/internal/crawler"
+)
+
+// Option configures a Crawler. Options are applied in order by New,
+// which reports the first invalid one.
+type Option func(*options) error
+
+// options is what an Option configures, kept unexported so the engine's
+// configuration can change without breaking callers.
+type options struct {
+	config *crawler.CrawlConfig
+}
+
+// Strategy is the order in which the frontier is crawled.
+type Strategy string
+
+const (
+	DepthFirst   Strategy = "depth-first"
+	BreadthFirst Strategy = "breadth-first"
+)
+
+// FetcherMode selects how pages are fetched.
+type FetcherMode string
+
+const (
+	FetchHTTP    FetcherMode = "http"    // plain HTTP requests (default)
+	FetchBrowser FetcherMode = "browser" // headless Chrome for every page
+	FetchAuto    FetcherMode = "auto"    // HTTP, with the browser for pages that need JavaScript
+)
+
+// WithMaxDepth limits how many links deep the crawl follows from the
+// target. The default is 3.
+func WithMaxDepth(depth int) Option {
+	return func(o *options) error {
+		if depth < 0 {
+			return fmt.Errorf("max depth must not be negative, got %d", depth)
+		}
+		o.config.MaxDepth = depth
+		return nil
+	}
+}
+
+// WithMaxPages stops the crawl after n pages; 0 means no limit. The
+// default is 500.
+func WithMaxPages(n int) Option {
+	return func(o *options) error {
+		if n < 0 {
+			return fmt.Errorf("max pages must not be negative, got %d", n)
+		}
+		o.config.MaxPages = n
+		return nil
+	}
+}
+
+// WithParallelism sets how many pages are fetched at once. The default
+// is 5.
+func WithParallelism(n int) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("parallelism must be at least 1, got %d", n)
+		}
+		o.config.Parallelism = n
+		return nil
+	}
+}
+
+// WithRateLimit sets the delay between requests. The default is 200ms.
+func WithRateLimit(delay time.Duration) Option {
+	return func(o *options) error {
+		o.config.RateLimit = delay
+		return nil
+	}
+}
+
+// WithStrategy sets the crawl order. The default is BreadthFirst.
+func WithStrategy(s Strategy) Option {
+	return func(o *options) error {
+		switch s {
+		case DepthFirst, BreadthFirst:
+			o.config.Strategy = crawler.Strategy(s)
+			return nil
+		}
+		return fmt.Errorf("unknown strategy %q", s)
+	}
+}
+
+// WithFetcher selects the built-in fetcher. The default is FetchHTTP.
+func WithFetcher(mode FetcherMode) Option {
+	return func(o *options) error {
+		switch mode {
+		case FetchHTTP, FetchBrowser, FetchAuto:
+			o.config.FetcherMode = crawler.FetcherMode(mode)
+			return nil
+		}
+		return fmt.Errorf("unknown fetcher %q", mode)
+	}
+}
+
+// WithUserAgent sets the User-Agent header.
+func WithUserAgent(ua string) Option {
+	return func(o *options) error {
+		o.config.UserAgent = ua
+		return nil
+	}
+}
+
+// WithHeader adds a header to every request.
+func WithHeader(name, value string) Option {
+	return func(o *options) error {
+		if name == "" {
+			return fmt.Errorf("header without a name")
+		}
+		o.config.CustomHeaders = append(o.config.CustomHeaders, name+": "+value)
+		return nil
+	}
+}
+
+// WithTimeout sets the per-request timeout. The default is 10s.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) error {
+		o.config.Timeout = d
+		return nil
+	}
+}
+
+// WithRetry sets how many times a failed request is retried. The
+// default is 1.
+func WithRetry(n int) Option {
+	return func(o *options) error {
+		if n < 0 {
+			return fmt.Errorf("retry must not be negative, got %d", n)
+		}
+		o.config.Retry = n
+		return nil
+	}
+}
+
+// WithProxy sends all requests through an HTTP or SOCKS5 proxy URL.
+func WithProxy(proxy string) Option {
+	return func(o *options) error {
+		o.config.Proxy = proxy
+		return nil
+	}
+}
+
+// WithExternalLinks also crawls pages on other hosts.
+func WithExternalLinks() Option {
+	return func(o *options) error {
+		o.config.AllowExternal = true
+		return nil
+	}
+}
+
+// IgnoreRobots crawls pages disallowed by robots.txt.
+func IgnoreRobots() Option {
+	return func(o *options) error {
+		o.config.RespectRobots = false
+		return nil
+	}
+}
+
+// WithExtractors runs only the named built-in or registered extractors.
+// Links are still followed when "links" is left out, just not reported.
+func WithExtractors(names ...string) Option {
+	return func(o *options) error {
+		o.config.Extractors = append(o.config.Extractors, names...)
+		return nil
+	}
+}
+
+// WithoutExtractors never runs the named extractors.
+func WithoutExtractors(names ...string) Option {
+	return func(o *options) error {
+		o.config.ExcludeExtractors = append(o.config.ExcludeExtractors, names...)
+		return nil
+	}
+}
+
+// WithExtractorTimeout bounds each extractor's run on a page; 0
+// disables the limit. The default is 30s.
+func WithExtractorTimeout(d time.Duration) Option {
+	return func(o *options) error {
+		o.config.ExtractorTimeout = d
+		return nil
+	}
+}
+
+// WithSecurityAudit adds the security header and cookie audit, whose
+// per-host rollup lands in the summary's Security field.
+func WithSecurityAudit() Option {
+	return func(o *options) error {
+		o.config.SecurityAudit = true
+		return nil
+	}
+}
+
+// WithAccessibilityAudit adds the WCAG accessibility checks, summarized in
+// the summary's Accessibility field.
+func WithAccessibilityAudit() Option {
+	return func(o *options) error {
+		o.config.A11yAudit = true
+		return nil
+	}
+}
+
+// WithCookieFile loads cookies from a Netscape cookies.txt, JSON or HAR
+// file before crawling.
+func WithCookieFile(path string) Option {
+	return func(o *options) error {
+		o.config.CookieFile = path
+		return nil
+	}
+}
+
+// WithHTTPCache keeps responses in dir and revalidates them on later
+// crawls.
+func WithHTTPCache(dir string) Option {
+	return func(o *options) error {
+		o.config.HTTPCacheDir = dir
+		return nil
+	}
+}
+
+// WithLinkCheck verifies every discovered link and asset, external ones
+// included, once the crawl ends. Results arrive through OnLinkChecked
+// and the summary.
+func WithLinkCheck() Option {
+	return func(o *options) error {
+		o.config.CheckLinks = true
+		return nil
+	}
+}
+
+// WithJSONL writes one JSON result per page to path.
+func WithJSONL(path string) Option {
+	return func(o *options) error {
+		o.config.JSONLOutput = path
+		return nil
+	}
+}
+
+// WithSEOReport writes the site-wide SEO audit to path, as JSON when it
+// ends in .json.
+func WithSEOReport(path string) Option {
+	return func(o *options) error {
+		o.config.SEOReport = path
+		return nil
+	}
+}
+
+// WithPluginConfig loads external plugins from a config file, as with
+// the command line's --config.
+func WithPluginConfig(path string) Option {
+	return func(o *options) error {
+		o.config.ConfigFile = path
+		return nil
+	}
+}
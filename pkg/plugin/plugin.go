@@ -14,6 +14,7 @@ import (
 type PageData struct {
 	URL             string               `json:"url"`
 	FinalURL        string               `json:"final_url"`
+	Redirects       []RedirectHop        `json:"redirects,omitempty"` // hops from URL to FinalURL, in order
 	StatusCode      int                  `json:"status_code"`
 	Headers         http.Header          `json:"-"`
 	RawHTML         string               `json:"-"`
@@ -26,7 +27,16 @@ type PageData struct {
 	Error           string               `json:"error,omitempty"`
 	Depth           int                  `json:"depth"`
 	ResponseSize    int                  `json:"response_size"`
+	ContentHash     string               `json:"content_hash,omitempty"` // sha256 of the raw body
+	SimHash         string               `json:"simhash,omitempty"`      // 64-bit SimHash of the visible text, hex
+	DuplicateOf     string               `json:"duplicate_of,omitempty"` // earlier page with the same or near-same content
+	Similarity      float64              `json:"similarity,omitempty"`   // estimated similarity to DuplicateOf (0-1)
 	Technologies    []string             `json:"technologies,omitempty"`
+	Interactions    []Interaction        `json:"interactions,omitempty"`
+	SPARoutes       []string             `json:"spa_routes,omitempty"`
+	ScreenshotPath  string               `json:"screenshot_path,omitempty"`
+	PDFPath         string               `json:"pdf_path,omitempty"`
+	CacheStatus     string               `json:"cache_status,omitempty"` // "hit", "miss" or empty when uncached
 }
 
 // InterceptedRequest represents an XHR/fetch request captured by the browser fetcher.
@@ -37,6 +47,35 @@ type InterceptedRequest struct {
 	ResourceType string `json:"resource_type,omitempty"`
 }
 
+// Interaction records a single scroll or click performed by the browser
+// fetcher and what new content it uncovered.
+type Interaction struct {
+	Action   string   `json:"action"` // "scroll" or "click"
+	Target   string   `json:"target,omitempty"`
+	NewLinks []string `json:"new_links,omitempty"`
+	NewXHRs  []string `json:"new_xhrs,omitempty"`
+}
+
+// RedirectHop is one response in a redirect chain.
+type RedirectHop struct {
+	URL        string        `json:"url"`
+	StatusCode int           `json:"status_code"`
+	Location   string        `json:"location"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// LinkStatus is the result of checking a discovered link or asset.
+type LinkStatus struct {
+	URL        string        `json:"url"`
+	StatusCode int           `json:"status_code"`
+	Method     string        `json:"method"` // HEAD, or GET when HEAD was rejected
+	FinalURL   string        `json:"final_url"`
+	Redirects  []RedirectHop `json:"redirects,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Broken     bool          `json:"broken"`
+	Sources    []string      `json:"sources"` // pages that refer to URL
+}
+
 // ExtractedItem represents a single piece of data extracted from a page.
 type ExtractedItem struct {
 	Type      string            `json:"type"` // e.g., "link", "email", "form", "phone", etc.
@@ -62,6 +101,37 @@ type CrawlSummary struct {
 	TotalItems  int            `json:"total_items"`
 	ItemsByType map[string]int `json:"items_by_type"`
 	Results     []CrawlResult  `json:"results"`
+
+	// DuplicateGroups lists pages with the same or near-same content; the
+	// first URL of each group is the page that was seen first.
+	DuplicateGroups [][]string `json:"duplicate_groups,omitempty"`
+
+	// LinkChecks holds the outcome of --check-links for every discovered URL.
+	LinkChecks []LinkStatus `json:"link_checks,omitempty"`
+
+	// Security rolls up the header and cookie audit per host.
+	Security []HostSecurity `json:"security,omitempty"`
+
+	// Accessibility counts the WCAG findings across all HTML pages.
+	Accessibility *AccessibilitySummary `json:"accessibility,omitempty"`
+}
+
+// HostSecurity summarises the security audit findings for one host.
+type HostSecurity struct {
+	Host       string         `json:"host"`
+	Pages      int            `json:"pages"`       // pages audited
+	Findings   int            `json:"findings"`    // findings across all pages
+	BySeverity map[string]int `json:"by_severity"` // severity -> findings
+	Issues     map[string]int `json:"issues"`      // issue -> pages affected
+}
+
+// AccessibilitySummary aggregates the accessibility findings of a crawl.
+type AccessibilitySummary struct {
+	Pages         int               `json:"pages"`          // HTML pages checked
+	Findings      int               `json:"findings"`       // findings across all pages
+	Issues        map[string]int    `json:"issues"`         // issue -> findings
+	PagesAffected map[string]int    `json:"pages_affected"` // issue -> pages affected
+	WCAG          map[string]string `json:"wcag"`           // issue -> success criterion
 }
 
 // ---------- Event Types ----------
@@ -71,6 +141,7 @@ type CrawlEvent struct {
 	Type    EventType
 	URL     string
 	Result  *CrawlResult
+	Link    *LinkStatus
 	Error   error
 	Stats   *CrawlStats
 	Message string
@@ -88,6 +159,8 @@ const (
 	EventCrawlStarted
 	EventCrawlFinished
 	EventProgress
+	EventLinkChecked
+	EventExtractorError
 )
 
 // CrawlStats holds real-time crawl statistics.
@@ -95,10 +168,16 @@ type CrawlStats struct {
 	PagesQueued    int            `json:"pages_queued"`
 	PagesCrawled   int            `json:"pages_crawled"`
 	PagesErrored   int            `json:"pages_errored"`
+	PagesDuplicate int            `json:"pages_duplicate"`
 	ItemsExtracted int            `json:"items_extracted"`
 	ItemsByType    map[string]int `json:"items_by_type"`
 	Elapsed        time.Duration  `json:"elapsed"`
 	PagesPerSec    float64        `json:"pages_per_sec"`
+	CacheHits      int            `json:"cache_hits"`
+	CacheMisses    int            `json:"cache_misses"`
+	CacheHitRatio  float64        `json:"cache_hit_ratio"`
+	LinksChecked   int            `json:"links_checked"`
+	LinksBroken    int            `json:"links_broken"`
 }
 
 // ---------- Plugin Interfaces ----------